@@ -0,0 +1,100 @@
+package mcts
+
+// arenaChunk is the number of cells and rows that the board arena allocates at once.
+const arenaChunk = 1 << 16
+
+// SetBoardArena sets whether the boards of tree nodes and rollouts are allocated from an arena
+// that is reused by every search that builds a new tree, instead of being allocated one by one.
+// This removes most of the allocations of board copies, at the cost of keeping the memory of
+// the largest tree. Boards of a previous tree, including the boards seen through NodeView, are
+// overwritten once a search builds a new tree, while a reused tree keeps its boards. The arena
+// does not apply to Evaluators that implement StateCloner. It is disabled by default.
+func (s *MCTS) SetBoardArena(arena bool) {
+	if !arena {
+		s.arena = nil
+	} else if s.arena == nil {
+		s.arena = &boardArena{}
+	}
+}
+
+// boardCopy copies board like cloneBoard, in the arena if it is enabled.
+func (s *MCTS) boardCopy(board [][]int) [][]int {
+	if _, ok := s.ev.(StateCloner); ok || s.arena == nil {
+		return cloneBoard(s.ev, board)
+	}
+	return s.arena.copyBoard(board)
+}
+
+// boardArena is a bump allocator of boards. Its chunks are kept when it is reset.
+type boardArena struct {
+	cells           [][]int
+	rows            [][][]int
+	cell, row       int
+	cellOff, rowOff int
+}
+
+// arenaMark is a position of a boardArena that it can be released to.
+type arenaMark struct {
+	cell, row       int
+	cellOff, rowOff int
+}
+
+// mark returns the current position of a.
+func (a *boardArena) mark() arenaMark {
+	return arenaMark{cell: a.cell, row: a.row, cellOff: a.cellOff, rowOff: a.rowOff}
+}
+
+// release frees every board allocated since m was taken.
+func (a *boardArena) release(m arenaMark) {
+	a.cell, a.row, a.cellOff, a.rowOff = m.cell, m.row, m.cellOff, m.rowOff
+}
+
+// reset frees every board of a.
+func (a *boardArena) reset() {
+	a.release(arenaMark{})
+}
+
+// copyBoard returns a copy of board allocated in a.
+func (a *boardArena) copyBoard(board [][]int) [][]int {
+	res := a.allocRows(len(board))
+	for i, row := range board {
+		res[i] = a.allocCells(len(row))
+		copy(res[i], row)
+	}
+	return res
+}
+
+// allocCells returns a slice of n cells whose capacity ends with it, so that appends copy it.
+func (a *boardArena) allocCells(n int) []int {
+	for {
+		if a.cell == len(a.cells) {
+			a.cells = append(a.cells, make([]int, maxInt(arenaChunk, n)))
+		}
+		if c := a.cells[a.cell]; a.cellOff+n <= len(c) {
+			a.cellOff += n
+			return c[a.cellOff-n : a.cellOff : a.cellOff]
+		}
+		a.cell, a.cellOff = a.cell+1, 0
+	}
+}
+
+// allocRows returns a slice of n rows like allocCells.
+func (a *boardArena) allocRows(n int) [][]int {
+	for {
+		if a.row == len(a.rows) {
+			a.rows = append(a.rows, make([][]int, maxInt(arenaChunk, n)))
+		}
+		if r := a.rows[a.row]; a.rowOff+n <= len(r) {
+			a.rowOff += n
+			return r[a.rowOff-n : a.rowOff : a.rowOff]
+		}
+		a.row, a.rowOff = a.row+1, 0
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,102 @@
+package mcts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBoardArena(t *testing.T) {
+	run := func(arena bool, reuse bool) (*MCTS, []ChildStat) {
+		ev := newTTTEval(4, 1)
+		s := New(ev, ev)
+		s.SetBoardArena(arena)
+		s.SetTreeReuse(reuse)
+		for i := 0; i < 3; i++ {
+			s.SearchIterations(newBoard(5, 5), 1, 2000)
+		}
+		return s, s.TopMoves(25)
+	}
+	for _, reuse := range []bool{false, true} {
+		_, want := run(false, reuse)
+		s, got := run(true, reuse)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected the same search with an arena, reuse %v", reuse)
+		}
+		// every stored board must still match its path
+		var walk func(n *treeNode, board [][]int)
+		walk = func(n *treeNode, board [][]int) {
+			if !reflect.DeepEqual(n.board, board) {
+				t.Fatalf("expected the board of a node to survive the search, reuse %v", reuse)
+			}
+			for _, ch := range n.children {
+				b := copyBoard(board)
+				s.ev.ApplyMove(b, ch.side, ch.move)
+				walk(ch, b)
+			}
+		}
+		walk(s.root, newBoard(5, 5))
+	}
+}
+
+func BenchmarkBoardArena(b *testing.B) {
+	for _, arena := range []bool{false, true} {
+		name := "default"
+		if arena {
+			name = "arena"
+		}
+		b.Run(name, func(b *testing.B) {
+			// tall boards take many allocations to copy
+			ev := newTableEval(16, 3, 3)
+			s := New(ev, ev)
+			s.SetBoardArena(arena)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s.SearchIterations(newBoard(16, 3), 1, 1000)
+			}
+		})
+	}
+}
+
+// tableEval is a tictactoe evaluator whose moves come from a table, so that only the search
+// allocates.
+type tableEval struct {
+	*tttEval
+	moves [][][3]*tttMove
+}
+
+func newTableEval(rows, cols, target int) *tableEval {
+	e := &tableEval{tttEval: newTTTEval(target, 1), moves: make([][][3]*tttMove, rows)}
+	for i := range e.moves {
+		e.moves[i] = make([][3]*tttMove, cols)
+		for j := range e.moves[i] {
+			for side := 1; side <= 2; side++ {
+				e.moves[i][j][side] = &tttMove{i: i, j: j, side: side}
+			}
+		}
+	}
+	return e
+}
+
+func (e *tableEval) Expand(board [][]int, side int) []Move {
+	var res []Move
+	for i, row := range board {
+		for j, v := range row {
+			if v == 0 {
+				res = append(res, e.moves[i][j][side])
+			}
+		}
+	}
+	return res
+}
+
+func (e *tableEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	rows, cols := len(board), len(board[0])
+	start := e.r.Intn(rows * cols)
+	for k := 0; k < rows*cols; k++ {
+		c := (start + k) % (rows * cols)
+		if board[c/cols][c%cols] == 0 {
+			return e.moves[c/cols][c%cols][currentPlayerSide]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package mcts
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// SetConcurrentBackprop sets whether backpropagation updates the visits and scores of nodes
+// with atomic operations, so that several goroutines can backpropagate into the same tree.
+// The statistics are updated atomically, while minimax values and proofs, which depend on several
+// fields of the children, are updated under a lock. It is disabled by default, since plain
+// arithmetic is faster for a single goroutine.
+func (s *MCTS) SetConcurrentBackprop(concurrent bool) {
+	s.concurrent = concurrent
+	if concurrent && s.statsMu == nil {
+		s.statsMu = &sync.Mutex{}
+	}
+}
+
+// backpropagateAtomic backpropagates like backpropagate with atomic updates of the statistics.
+func (s *MCTS) backpropagateAtomic(n *treeNode, o outcome) {
+	atomic.AddInt64(&s.rollouts, 1)
+	if o.terminal {
+		atomic.AddInt64(&s.terminals, 1)
+	}
+	s.statsMu.Lock()
+	s.rolloutWins[o.winner]++
+	s.statsMu.Unlock()
+	end := n.depth + o.plies
+	w := s.confidence(o)
+	for n != nil {
+		atomic.AddInt64(&n.visits, 1)
+		atomic.AddInt64(&n.iters, 1)
+		atomic.AddInt64(&n.plySum, int64(end-n.depth))
+		r := s.discounted(s.reward(o, n.side), end-n.depth)
+		addFloat64(&n.winScore, w*r)
+		addFloat64(&n.sqScore, w*r*r)
+		addFloat64(&n.unweighted, 1-w)
+		if n.shared != nil {
+			atomic.AddInt64(&n.shared.visits, 1)
+			addFloat64(&n.shared.winScore, r)
+		}
+		if n.parent == nil {
+			addFloat64(&s.rolloutScore, s.discounted(s.reward(o, n.toMove), end))
+		}
+		if s.backupMode == Minimax || s.solving() {
+			s.statsMu.Lock()
+			if s.backupMode == Minimax {
+				n.updateMinimaxAtomic()
+			}
+			if s.solving() {
+				n.updateProof(s.draw)
+			}
+			s.statsMu.Unlock()
+		}
+		n = n.parent
+	}
+}
+
+// updateMinimaxAtomic recomputes the minimax value of n like updateMinimax, loading the
+// statistics that other goroutines update atomically.
+func (n *treeNode) updateMinimaxAtomic() {
+	if n.gameOver || len(n.children) == 0 {
+		return
+	}
+	var best *treeNode
+	var bestVal float64
+	for _, ch := range n.children {
+		if atomic.LoadInt64(&ch.visits) == 0 {
+			continue
+		}
+		v := ch.mmValue
+		if !ch.gameOver && len(ch.children) == 0 {
+			v = atomicValue(ch)
+		}
+		if best == nil || v > bestVal {
+			best, bestVal = ch, v
+		}
+	}
+	if best == nil {
+		n.mmValue = atomicValue(n)
+		return
+	}
+	if best.side != n.side {
+		bestVal = -bestVal
+	}
+	n.mmValue = bestVal
+}
+
+// atomicValue returns the mean evaluation of n like value, loading its statistics atomically.
+func atomicValue(n *treeNode) float64 {
+	visits := atomic.LoadInt64(&n.visits)
+	if visits == 0 {
+		return 0
+	}
+	return loadFloat64(&n.winScore) / math.Max(1, float64(visits)-loadFloat64(&n.unweighted))
+}
+
+// loadFloat64 atomically loads *addr.
+func loadFloat64(addr *float64) float64 {
+	return math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(addr))))
+}
+
+// addFloat64 atomically adds delta to *addr.
+func addFloat64(addr *float64, delta float64) {
+	p := (*uint64)(unsafe.Pointer(addr))
+	for {
+		old := atomic.LoadUint64(p)
+		if atomic.CompareAndSwapUint64(p, old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mcts
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentBackprop(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetConcurrentBackprop(true)
+	s.SetKeepSearchingAfterProof(true)
+	s.SearchIterations(newBoard(3, 3), 1, 50)
+	var leaf *treeNode
+	for n := s.root; len(n.children) > 0; n = n.children[0] {
+		leaf = n.children[0]
+	}
+	visits, score := make(map[*treeNode]int64), make(map[*treeNode]float64)
+	for n := leaf; n != nil; n = n.parent {
+		visits[n], score[n] = n.visits, n.winScore
+	}
+	const goroutines, perGoroutine = 16, 500
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				s.backpropagate(leaf, outcome{winner: leaf.side})
+			}
+		}()
+	}
+	wg.Wait()
+	for n := leaf; n != nil; n = n.parent {
+		if n.visits-visits[n] != goroutines*perGoroutine {
+			t.Fatalf("expected %v more visits at depth %v, got %v", goroutines*perGoroutine, n.depth, n.visits-visits[n])
+		}
+		want := float64(goroutines * perGoroutine)
+		if n.side != leaf.side {
+			want = -want
+		}
+		if n.winScore-score[n] != want {
+			t.Fatalf("expected a score change of %v at depth %v, got %v", want, n.depth, n.winScore-score[n])
+		}
+	}
+}
+
+func TestConcurrentPhaseTimes(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetConcurrentBackprop(true)
+	s.SetPhaseBudgetSplit(0.5)
+	const goroutines, perGoroutine = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				s.addPhaseTime(&s.expandTime, time.Millisecond)
+				s.addPhaseTime(&s.rolloutTime, 2*time.Millisecond)
+				s.overExpandShare()
+			}
+		}()
+	}
+	wg.Wait()
+	if expand, rollout := s.PhaseTimes(); expand != goroutines*perGoroutine*time.Millisecond || rollout != 2*expand {
+		t.Fatalf("expected every phase time to be added, got %v and %v", expand, rollout)
+	}
+}
+
+func TestConcurrentBackpropProofs(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetConcurrentBackprop(true)
+	s.SetBackupMode(Minimax)
+	s.SetKeepSearchingAfterProof(true)
+	s.SearchIterations(newBoard(3, 3), 1, 300)
+	// the proofs of the inner nodes are rebuilt by the concurrent backpropagations
+	var leaves []*treeNode
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if len(n.children) == 0 {
+			leaves = append(leaves, n)
+			return
+		}
+		if !n.gameOver {
+			n.proven = false
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(s.root)
+	visits := s.root.visits
+	const goroutines = 8
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < len(leaves); i += goroutines {
+				s.backpropagate(leaves[i], outcome{winner: leaves[i].side})
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s.root.visits-visits != int64(len(leaves)) {
+		t.Fatalf("expected %v more root visits, got %v", len(leaves), s.root.visits-visits)
+	}
+}
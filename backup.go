@@ -0,0 +1,96 @@
+package mcts
+
+// BackupMode determines how node values are backed up for move selection.
+type BackupMode int
+
+const (
+	// Average uses the mean rollout value of each node. This is the default.
+	Average BackupMode = iota
+	// Minimax uses the negamax of the children values of each node, where leaves use
+	// their mean rollout value and terminal nodes their exact outcome.
+	// Visit counts are still accumulated as in Average.
+	Minimax
+)
+
+// SetBackupMode sets the backup mode used for selection and for choosing the final move.
+func (s *MCTS) SetBackupMode(mode BackupMode) {
+	s.backupMode = mode
+}
+
+// terminalValue returns the exact value of a terminal node from the perspective of n.side,
+// where draw is the winner of a draw and drawReward its value.
+func (n *treeNode) terminalValue(draw int, drawReward float64) float64 {
+	switch n.winner {
+	case draw:
+		return drawReward
+	case n.side:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// minimaxValue returns the minimax value of n from the perspective of n.side.
+func (n *treeNode) minimaxValue() float64 {
+	if n.gameOver {
+		// the minimax value of a terminal node is set to its terminal value when it is created
+		return n.mmValue
+	}
+	if len(n.children) == 0 {
+		return n.value()
+	}
+	return n.mmValue
+}
+
+// updateMinimax recomputes the minimax value of n from its children.
+// The player to move at n picks the child with the highest value for that player.
+func (n *treeNode) updateMinimax() {
+	if n.gameOver || len(n.children) == 0 {
+		return
+	}
+	var best *treeNode
+	var bestVal float64
+	for _, ch := range n.children {
+		if ch.visits == 0 {
+			continue
+		}
+		if v := ch.minimaxValue(); best == nil || v > bestVal {
+			best, bestVal = ch, v
+		}
+	}
+	if best == nil {
+		n.mmValue = n.value()
+		return
+	}
+	if best.side != n.side {
+		bestVal = -bestVal
+	}
+	n.mmValue = bestVal
+}
+
+// bestMinimaxChild returns the child of n with the highest minimax value,
+// breaking ties by visits. Children with fewer than minVisits visits are ignored,
+// unless no child has enough visits.
+func bestMinimaxChild(n *treeNode, minVisits int64) *treeNode {
+	if len(n.children) == 0 {
+		panic("could not find any children")
+	}
+	var res *treeNode
+	for _, ch := range n.children {
+		if ch.visits < minVisits {
+			continue
+		}
+		if res == nil {
+			res = ch
+			continue
+		}
+		v, rv := ch.minimaxValue(), res.minimaxValue()
+		if v > rv || (v == rv && ch.visits > res.visits) {
+			res = ch
+		}
+	}
+	if res == nil {
+		return bestMinimaxChild(n, 0)
+	}
+	return res
+}
@@ -0,0 +1,30 @@
+package mcts
+
+import "testing"
+
+func TestMinimaxBackupFindsForcedWin(t *testing.T) {
+	// X wins at (2, 2), which is the last move returned by the expander.
+	board := [][]int{
+		{0, 0, 0},
+		{2, 2, 0},
+		{1, 1, 0},
+	}
+	itersToFind := func(mode BackupMode) int {
+		for iters := 1; iters <= 500; iters++ {
+			ev := newTTTEval(3, 1)
+			s := New(ev, ev)
+			s.SetBackupMode(mode)
+			s.SetKeepSearchingAfterProof(true)
+			m, _ := s.Search(copyBoard(board), 1, 0, 0, iters)
+			if mov := m.(*tttMove); mov.i == 2 && mov.j == 2 {
+				return iters
+			}
+		}
+		t.Fatalf("backup mode %v did not find the winning move", mode)
+		return 0
+	}
+	avg, mm := itersToFind(Average), itersToFind(Minimax)
+	if mm >= avg {
+		t.Fatalf("expected minimax backup to find the win sooner, got %v iterations for minimax and %v for average", mm, avg)
+	}
+}
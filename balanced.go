@@ -0,0 +1,32 @@
+package mcts
+
+// SearchBalanced searches each of rootMoves played by side on board with its own budget instead
+// of letting the UCB values share a budget among the root Moves, which makes the statistics of
+// the given Moves comparable for the deep analysis of specific lines. Every Move runs
+// perMoveBudget, whose MaxDepth is relative to its own node, and the statistics are returned
+// in the order of rootMoves, or empty if board is a finished game. The searched tree is retained
+// like the tree of Search.
+func (s *MCTS) SearchBalanced(board [][]int, side int, rootMoves []Move, perMoveBudget SearchBudget) []ChildStat {
+	s.pause.enterRun()
+	defer s.pause.exitRun()
+	b := perMoveBudget
+	if b.Duration <= 0 && b.MaxIters <= 0 {
+		b.MaxIters = s.defaultIters
+	}
+	if b.MaxDepth > 0 {
+		b.MaxDepth++
+	}
+	root := s.newRoot(board, side)
+	s.root = root
+	s.memUsed = treeFootprint(root)
+	s.ucbGen++
+	if !root.gameOver {
+		for _, m := range rootMoves {
+			s.addChild(root, &treeNode{}, m, root.board)
+		}
+	}
+	for _, ch := range root.children {
+		s.run(ch, b, newBudgetClock(s.clock))
+	}
+	return childStats(root)
+}
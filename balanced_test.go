@@ -0,0 +1,42 @@
+package mcts
+
+import "testing"
+
+func TestSearchBalanced(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	moves := []Move{
+		&tttMove{i: 0, j: 0, side: 1},
+		&tttMove{i: 1, j: 1, side: 1},
+		&tttMove{i: 3, j: 2, side: 1},
+	}
+	stats := s.SearchBalanced(newBoard(4, 4), 1, moves, SearchBudget{MaxIters: 300})
+	if len(stats) != len(moves) {
+		t.Fatalf("expected stats of %v moves, got %v", len(moves), len(stats))
+	}
+	for i, st := range stats {
+		if st.Move != moves[i] {
+			t.Fatal("expected the stats in the order of the moves")
+		}
+		if st.SubtreeVisits != 300 {
+			t.Fatalf("expected 300 iterations for every move, got %v", st.SubtreeVisits)
+		}
+		if st.Visits < 300 || st.Visits > stats[0].Visits*2 {
+			t.Fatalf("expected comparable visits, got %v and %v", st.Visits, stats[0].Visits)
+		}
+	}
+	if len(s.TopMoves(5)) != 3 {
+		t.Fatal("expected the balanced tree to be retained")
+	}
+}
+
+func TestSearchBalancedFinished(t *testing.T) {
+	ev := &terminalEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	board := newBoard(3, 3)
+	board[0][0], board[0][1], board[0][2] = 2, 2, 2
+	stats := s.SearchBalanced(board, 1, []Move{&tttMove{i: 1, j: 1, side: 1}}, SearchBudget{MaxIters: 10})
+	if len(stats) != 0 || !s.root.gameOver || s.root.winner != 2 {
+		t.Fatalf("expected no stats for a finished game, got %v", stats)
+	}
+}
@@ -0,0 +1,41 @@
+package mcts
+
+// Book is an opening book that maps positions to the Moves to play in them.
+// Lookup returns the booked Move of side on board and true, or false if board is not in the
+// book. Implementations that key positions canonically, for example with Canonicalizer, also
+// find symmetric positions, in which case the returned Move must be valid for board itself.
+type Book interface {
+	Lookup(board [][]int, side int) (Move, bool)
+}
+
+// SetBook sets the book that Search, SearchWithBudget and SearchE consult before searching.
+// If the position is in the book, its Move is returned immediately with 0 visits and the tree
+// of the previous search is discarded. If verify is true, the position is searched as usual
+// anyway and the booked Move is returned unless the search proves that it loses or that it
+// achieves a worse outcome than the proven outcome of the position, in which case the searched
+// Move is returned. A booked Move that the search has not proven is kept. A nil book, which is the default, disables the book.
+func (s *MCTS) SetBook(book Book, verify bool) {
+	s.book = book
+	s.verifyBook = verify
+}
+
+// bookMove returns the result of a search of board within b whose booked Move is m.
+func (s *MCTS) bookMove(board [][]int, side int, b SearchBudget, m Move) (Move, int64) {
+	if !s.verifyBook {
+		s.root = nil
+		return m, 0
+	}
+	root := s.search(board, side, b)
+	if root.gameOver {
+		return nil, 0
+	}
+	for _, ch := range root.children {
+		if s.moveEqual(ch.move, m) {
+			if s.lost(ch) || root.proven && ch.proven && ch.provenWinner != root.provenWinner {
+				return s.bestChild(root).move, root.visits
+			}
+			break
+		}
+	}
+	return m, root.visits
+}
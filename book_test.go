@@ -0,0 +1,64 @@
+package mcts
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stubBook books a single move of side 1 on a single board.
+type stubBook struct {
+	board [][]int
+	move  *tttMove
+}
+
+func (b *stubBook) Lookup(board [][]int, side int) (Move, bool) {
+	if side != 1 || !reflect.DeepEqual(board, b.board) {
+		return nil, false
+	}
+	return b.move, true
+}
+
+func TestBook(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	corner := &tttMove{i: 0, j: 0, side: 1}
+	s.SetBook(&stubBook{board: newBoard(3, 3), move: corner}, false)
+	if m, visits := s.SearchIterations(newBoard(3, 3), 1, 100); m != corner || visits != 0 {
+		t.Fatalf("expected the booked move without a search, got %v with %v visits", m, visits)
+	}
+	if s.root != nil {
+		t.Fatal("expected no tree after a booked move")
+	}
+	board := [][]int{
+		{1, 0, 0},
+		{0, 0, 0},
+		{0, 0, 0},
+	}
+	if m, visits := s.SearchIterations(board, 1, 100); m == nil || visits == 0 {
+		t.Fatalf("expected a search of a position that is not in the book, got %v with %v visits", m, visits)
+	}
+
+	// X wins with (0, 2), while (2, 2) lets O win
+	board = [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	blunder := &tttMove{i: 2, j: 2, side: 1}
+	s.SetBook(&stubBook{board: cloneBoard(ev, board), move: blunder}, true)
+	// the minimum iterations keep searching the proven position until the blunder is proven
+	m, visits := s.SearchWithBudget(board, 1, SearchBudget{MinIters: 2000, MaxIters: 2000})
+	if mov := m.(*tttMove); mov.i != 0 || mov.j != 2 || visits == 0 {
+		t.Fatalf("expected the verifying search to replace a losing booked move, got %v", m)
+	}
+	s.SetBook(&stubBook{board: cloneBoard(ev, board), move: &tttMove{i: 0, j: 2, side: 1}}, true)
+	if m, _ := s.SearchIterations(board, 1, 2000); m.(*tttMove).j != 2 || m.(*tttMove).i != 0 {
+		t.Fatalf("expected a verified booked move, got %v", m)
+	}
+	// the position is proven by (0, 2), while (1, 2) is left unproven by the small budget
+	block := &tttMove{i: 1, j: 2, side: 1}
+	s.SetBook(&stubBook{board: cloneBoard(ev, board), move: block}, true)
+	if m, _ := s.SearchIterations(board, 1, 20); m != block {
+		t.Fatalf("expected an unproven booked move to be kept, got %v", m)
+	}
+}
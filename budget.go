@@ -0,0 +1,144 @@
+package mcts
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SearchBudget holds the caps of a search.
+// Duration and MaxIters are independent caps and the search stops at whichever is hit first,
+// as described for Search. MaxDepth limits the depth of expanded nodes if it is greater than 0.
+// MinIters is the minimum number of iterations, which are run even if the other caps are hit.
+type SearchBudget struct {
+	Duration time.Duration
+	MaxDepth int
+	MaxIters int
+	MinIters int
+}
+
+// done reports whether a search that has run iter iterations in elapsed time should stop.
+func (b SearchBudget) done(iter int, elapsed time.Duration) bool {
+	if iter < b.MinIters {
+		return false
+	}
+	if b.MaxIters > 0 && iter >= b.MaxIters {
+		return true
+	}
+	if b.Duration > 0 {
+		return elapsed >= b.Duration
+	}
+	return b.MaxIters <= 0
+}
+
+// maxCheckGap is the target time between two clock reads of a search. The deadline of a search
+// is overshot by about this much at most.
+const maxCheckGap = time.Millisecond
+
+// budgetClock measures the elapsed time of a search, reading the clock only every step
+// iterations since a clock read is a measurable part of an iteration of a cheap game.
+// step doubles while clock reads are closer than maxCheckGap and halves when they are further,
+// and it is capped by the iterations that the remaining budget allows at the cost of the
+// iterations since the last read, so that no read is planned past the deadline after the
+// iterations have become more expensive.
+type budgetClock struct {
+	clock   Clock
+	t0      time.Time
+	elapsed time.Duration
+	step    int
+	next    int
+	read    int
+}
+
+func newBudgetClock(clock Clock) *budgetClock {
+	return &budgetClock{clock: clock, t0: clock.Now(), step: 1}
+}
+
+// since returns the elapsed time after iter iterations of a search with the given time budget,
+// which is the time of the last clock read if the clock is not due. A budget less than or equal
+// to 0 does not cap the step.
+func (c *budgetClock) since(iter int, budget time.Duration) time.Duration {
+	if iter < c.next {
+		return c.elapsed
+	}
+	elapsed := c.clock.Now().Sub(c.t0)
+	gap := elapsed - c.elapsed
+	c.elapsed = elapsed
+	if gap < maxCheckGap/2 {
+		c.step *= 2
+	} else if gap > maxCheckGap && c.step > 1 {
+		c.step /= 2
+	}
+	if iters := iter - c.read; budget > 0 && iters > 0 && gap > 0 {
+		perIter := gap / time.Duration(iters)
+		if remaining := int((budget - elapsed) / perIter); remaining < c.step {
+			c.step = remaining
+		}
+		if c.step < 1 {
+			c.step = 1
+		}
+	}
+	c.read = iter
+	c.next = iter + c.step
+	return elapsed
+}
+
+// SetPhaseBudgetSplit sets the share of the search time that expansions may take, for games
+// whose Expand and ApplyMove have very different costs. The search measures the time of
+// expansions and rollouts, and an iteration whose expansion is over its share runs a rollout
+// from the selected leaf without expanding it, which balances the growth of the tree against the
+// number of rollouts. The root is always expanded. With SetConcurrentBackprop the times are
+// accumulated atomically. A fraction less than or equal to 0, which is the default, does not
+// measure the phases.
+func (s *MCTS) SetPhaseBudgetSplit(expandFraction float64) {
+	s.phaseSplit = expandFraction
+}
+
+// PhaseTimes returns the time spent in expansions and in rollouts during the last search, which
+// is only measured if SetPhaseBudgetSplit is set.
+func (s *MCTS) PhaseTimes() (expand, rollout time.Duration) {
+	return loadDuration(&s.expandTime), loadDuration(&s.rolloutTime)
+}
+
+// overExpandShare reports whether expansions took more than their share of the measured time.
+func (s *MCTS) overExpandShare() bool {
+	if s.phaseSplit <= 0 {
+		return false
+	}
+	expand, rollout := s.PhaseTimes()
+	return float64(expand) > s.phaseSplit*float64(expand+rollout)
+}
+
+// addPhaseTime adds elapsed to the phase time *d, atomically with concurrent backpropagation.
+func (s *MCTS) addPhaseTime(d *time.Duration, elapsed time.Duration) {
+	if s.concurrent {
+		atomic.AddInt64((*int64)(d), int64(elapsed))
+		return
+	}
+	*d += elapsed
+}
+
+// loadDuration atomically loads *d.
+func loadDuration(d *time.Duration) time.Duration {
+	return time.Duration(atomic.LoadInt64((*int64)(d)))
+}
+
+// Clock is the source of time of a search.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock sets the clock that time limits and subtree timing are measured with, which allows
+// deterministic tests of time limited searches. A nil clock, which is the default, uses the
+// wall clock.
+func (s *MCTS) SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	s.clock = c
+}
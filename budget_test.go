@@ -0,0 +1,183 @@
+package mcts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchWithBudget(t *testing.T) {
+	run := func(search func(s *MCTS) (Move, int64)) ([]ChildStat, Move, int64) {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		m, visits := search(s)
+		return s.TopMoves(9), m, visits
+	}
+	stats, m, visits := run(func(s *MCTS) (Move, int64) {
+		return s.Search(newBoard(3, 3), 1, time.Hour, 3, 300)
+	})
+	bstats, bm, bvisits := run(func(s *MCTS) (Move, int64) {
+		return s.SearchWithBudget(newBoard(3, 3), 1, SearchBudget{Duration: time.Hour, MaxDepth: 3, MaxIters: 300})
+	})
+	if !reflect.DeepEqual(stats, bstats) || !reflect.DeepEqual(m, bm) || visits != bvisits {
+		t.Fatal("expected identical results for the positional and the budget call")
+	}
+}
+
+func TestSearchBudgetMinIters(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	s.SearchWithBudget(newBoard(3, 3), 1, SearchBudget{Duration: time.Nanosecond, MinIters: 50})
+	if len(rs.moves) != 50 {
+		t.Fatalf("expected 50 iterations, got %v", len(rs.moves))
+	}
+}
+
+func TestSearchDeadlineOvershoot(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	// cheap iterations make the clock reads sparse
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s.SetClock(clock)
+	s.SetTrajectorySink(&tickingSink{clock: clock, step: 10 * time.Microsecond})
+	s.Search(newBoard(5, 5), 1, 50*time.Millisecond, 0, 0)
+	elapsed := clock.now.Sub(time.Unix(0, 0))
+	if elapsed < 50*time.Millisecond || elapsed > 50*time.Millisecond+maxCheckGap {
+		t.Fatalf("expected the search to stop close to its deadline, took %v", elapsed)
+	}
+}
+
+func TestSearchCostJump(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	// the iterations become ten times more expensive shortly before the deadline, while the
+	// clock reads are still sparse
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s.SetClock(clock)
+	sink := &jumpingSink{after: 4500, jump: 100 * time.Microsecond}
+	sink.clock, sink.step = clock, 10*time.Microsecond
+	s.SetTrajectorySink(sink)
+	s.Search(newBoard(5, 5), 1, 50*time.Millisecond, 0, 0)
+	elapsed := clock.now.Sub(time.Unix(0, 0))
+	if elapsed < 50*time.Millisecond || elapsed > 50*time.Millisecond+maxCheckGap {
+		t.Fatalf("expected the search to stop close to its deadline, took %v", elapsed)
+	}
+}
+
+func BenchmarkClockCheck(b *testing.B) {
+	b.Run("every iteration", func(b *testing.B) {
+		t0 := time.Now()
+		for i := 0; i < b.N; i++ {
+			_ = time.Since(t0)
+		}
+	})
+	b.Run("amortized", func(b *testing.B) {
+		c := newBudgetClock(realClock{})
+		for i := 0; i < b.N; i++ {
+			c.since(i, 0)
+		}
+	})
+}
+
+func BenchmarkSearchDuration(b *testing.B) {
+	// a shallow tree keeps the iterations cheap, so that the clock reads matter
+	ev := newTTTEval(3, 1)
+	var iters int64
+	for i := 0; i < b.N; i++ {
+		s := New(ev, ev)
+		s.Search(newBoard(3, 3), 1, 10*time.Millisecond, 1, 0)
+		iters += s.rollouts
+	}
+	b.ReportMetric(float64(iters)/b.Elapsed().Seconds(), "iters/s")
+}
+
+// fakeClock is a clock that only moves when it is advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// tickingSink advances a clock by step for every recorded trajectory, which is once per iteration.
+type tickingSink struct {
+	clock *fakeClock
+	step  time.Duration
+}
+
+func (ts *tickingSink) Record(moves []Move, winner int) {
+	ts.clock.now = ts.clock.now.Add(ts.step)
+}
+
+// jumpingSink advances a clock like tickingSink, whose step becomes jump once after
+// trajectories have been recorded.
+type jumpingSink struct {
+	tickingSink
+	after    int
+	jump     time.Duration
+	recorded int
+}
+
+func (ts *jumpingSink) Record(moves []Move, winner int) {
+	ts.recorded++
+	if ts.recorded > ts.after {
+		ts.step = ts.jump
+	}
+	ts.tickingSink.Record(moves, winner)
+}
+
+func TestSearchFakeClock(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s.SetClock(clock)
+	s.SetTrajectorySink(&tickingSink{clock: clock, step: time.Millisecond})
+	s.Search(newBoard(4, 4), 1, 37*time.Millisecond, 0, 0)
+	if s.rollouts != 37 {
+		t.Fatalf("expected 37 iterations, got %v", s.rollouts)
+	}
+}
+
+// phasedEval is a tictactoe evaluator whose Expand advances a clock by expand and whose
+// rollout moves advance it by rollout.
+type phasedEval struct {
+	*tttEval
+	clock           *fakeClock
+	expand, rollout time.Duration
+}
+
+func (e *phasedEval) Expand(board [][]int, side int) []Move {
+	e.clock.now = e.clock.now.Add(e.expand)
+	return e.tttEval.Expand(board, side)
+}
+
+func (e *phasedEval) RandomMove(board [][]int, side int) Move {
+	e.clock.now = e.clock.now.Add(e.rollout)
+	return e.tttEval.RandomMove(board, side)
+}
+
+func TestPhaseBudgetSplit(t *testing.T) {
+	share := func(split float64) (float64, *MCTS) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ev := &phasedEval{tttEval: newTTTEval(4, 1), clock: clock, expand: 10 * time.Millisecond, rollout: time.Millisecond}
+		s := New(ev, ev)
+		s.SetClock(clock)
+		s.SetPhaseBudgetSplit(split)
+		s.Search(newBoard(5, 5), 1, 0, 0, 300)
+		expand, rollout := s.PhaseTimes()
+		return float64(expand) / float64(expand+rollout), s
+	}
+	if full, _ := share(1); full < 0.3 {
+		t.Fatalf("expected expensive expansions to take a large share, got %v", full)
+	}
+	got, s := share(0.1)
+	if got < 0.05 || got > 0.15 {
+		t.Fatalf("expected expansions to take about 10%% of the time, got %v", got)
+	}
+	if len(s.root.children) != 25 {
+		t.Fatalf("expected the root to be expanded, got %v children", len(s.root.children))
+	}
+}
@@ -0,0 +1,33 @@
+package mcts
+
+// SetCompactNodes sets whether tree nodes of DeltaMoves are stored without a board.
+// This trades the CPU time spent rebuilding boards from the path for the memory of a board
+// copy per node, which dominates the size of the tree. The root always keeps its board, as do
+// nodes of Moves that do not implement DeltaMove.
+func (s *MCTS) SetCompactNodes(compact bool) {
+	s.compactNodes = compact
+}
+
+// boardOf returns the board of n without copying it if n stores one,
+// and a rebuilt board otherwise. The result must not be modified.
+func (s *MCTS) boardOf(n *treeNode) [][]int {
+	if n.board != nil {
+		return n.board
+	}
+	return s.nodeBoard(n)
+}
+
+// nodeBoard returns a copy of the board of n, which is rebuilt by applying the moves from
+// the nearest ancestor with a stored board if n does not store one.
+func (s *MCTS) nodeBoard(n *treeNode) [][]int {
+	var path []DeltaMove
+	for n.board == nil {
+		path = append(path, n.move.(DeltaMove))
+		n = n.parent
+	}
+	board := s.boardCopy(n.board)
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].Apply(board)
+	}
+	return board
+}
@@ -0,0 +1,55 @@
+package mcts
+
+import "testing"
+
+func TestCompactNodes(t *testing.T) {
+	board := newBoard(4, 4)
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.Search(copyBoard(board), 1, 0, 0, 500)
+	cev := newTTTEval(3, 1)
+	cs := New(cev, cev)
+	cs.SetCompactNodes(true)
+	cs.Search(copyBoard(board), 1, 0, 0, 500)
+
+	want, got := s.TopMoves(16), cs.TopMoves(16)
+	for i := range want {
+		if want[i].Visits != got[i].Visits || want[i].Value != got[i].Value {
+			t.Fatalf("expected the same statistics with compact nodes, got %v and %v", want[i], got[i])
+		}
+	}
+	if cs.EstimatedMemory() >= s.EstimatedMemory() {
+		t.Fatalf("expected compact nodes to use less memory, got %v and %v", cs.EstimatedMemory(), s.EstimatedMemory())
+	}
+	var walk func(n, cn *treeNode)
+	walk = func(n, cn *treeNode) {
+		if cn.parent != nil && cn.board != nil {
+			t.Fatal("expected compact nodes without boards")
+		}
+		if !boardsEqual(n.board, cs.boardOf(cn)) {
+			t.Fatal("expected rebuilt boards to match stored boards")
+		}
+		for i := range n.children {
+			walk(n.children[i], cn.children[i])
+		}
+	}
+	walk(s.root, cs.root)
+}
+
+func benchmarkSearchCompact(b *testing.B, compact bool) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ev := newTTTEval(4, 1)
+		s := New(ev, ev)
+		s.SetCompactNodes(compact)
+		s.Search(newBoard(6, 6), 1, 0, 0, 200)
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	benchmarkSearchCompact(b, false)
+}
+
+func BenchmarkSearchCompactNodes(b *testing.B) {
+	benchmarkSearchCompact(b, true)
+}
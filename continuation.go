@@ -0,0 +1,114 @@
+package mcts
+
+// SearchContinuation searches the position after moves are played from the root of the
+// retained tree, which answers what the best continuation after those moves is without
+// discarding the rest of the tree. The tree is descended by Move equality and a Move that is not
+// yet a child is added. Iterations then select from the reached node, while their results are
+// backpropagated up to the root as usual. The MaxDepth of b is relative to the reached node.
+// It returns a nil Move and 0 visits if no search has been run yet, the last search merged
+// several trees like SearchParallel and SearchDeterminized, or the moves end the game.
+func (s *MCTS) SearchContinuation(moves []Move, b SearchBudget) (Move, int64) {
+	if s.root == nil || merged(s.root) {
+		return nil, 0
+	}
+	s.pause.enterRun()
+	defer s.pause.exitRun()
+	n := s.root
+	for _, m := range moves {
+		if n.gameOver {
+			return nil, 0
+		}
+		n = s.descend(n, m)
+	}
+	if b.Duration <= 0 && b.MaxIters <= 0 {
+		b.MaxIters = s.defaultIters
+	}
+	if b.MaxDepth > 0 {
+		b.MaxDepth += n.depth
+	}
+	s.ucbGen++
+	s.run(n, b, newBudgetClock(s.clock))
+	if n.gameOver {
+		return nil, 0
+	}
+	if len(n.children) == 0 {
+		return s.fallbackMove(n, s.toMove(n)), n.visits
+	}
+	return s.bestChild(n).move, n.visits
+}
+
+// descend returns the child of n whose Move equals m, which is added if there is none.
+func (s *MCTS) descend(n *treeNode, m Move) *treeNode {
+	if len(n.children) == 0 && !n.hasPending() {
+		s.expand(n, 0)
+	}
+	for _, ch := range n.children {
+		if s.moveEqual(ch.move, m) {
+			return ch
+		}
+	}
+	for i, pm := range n.pending {
+		if s.moveEqual(pm, m) {
+			n.pending = append(n.pending[:i:i], n.pending[i+1:]...)
+			break
+		}
+	}
+	child := &treeNode{}
+	s.addChild(n, child, m, s.expansionBoard(n))
+	return child
+}
+
+// AddVisits runs n more iterations on the root of the retained tree without advancing it, and
+// returns the best Move and the root visits like Search. Calling it repeatedly refines the
+// result of the last search for as long as time allows. It returns a nil Move and 0 visits if
+// no search has been run yet, the last search merged several trees or the root is a finished
+// game.
+func (s *MCTS) AddVisits(n int) (Move, int64) {
+	return s.SearchContinuation(nil, SearchBudget{MaxIters: n})
+}
+
+// ExtendPV runs up to extraIters more iterations on the retained tree that are restricted to the
+// subtree of the principal variation, which deepens the chosen line to confirm that it is not a
+// shallow mistake. Every iteration selects from the current best root child, so the extension
+// follows the principal variation if another Move takes over. It returns the principal
+// variation afterwards, which follows the final Move choice from the root down to a leaf, and
+// whether it differs from the principal variation before the extension, for example because a
+// reply was refuted. The iterations keep the MaxDepth of the last search. It stops early if the
+// best Move ends the game, and returns nil if no search has been run yet, the last search merged
+// several trees or the root has no children.
+func (s *MCTS) ExtendPV(extraIters int) (pv []Move, changed bool) {
+	root := s.root
+	if root == nil || merged(root) || root.gameOver || len(root.children) == 0 {
+		return nil, false
+	}
+	s.pause.enterRun()
+	defer s.pause.exitRun()
+	before := s.principalVariation(root)
+	for i := 0; i < extraIters; i++ {
+		s.pause.wait()
+		best := s.bestChild(root)
+		if best.gameOver {
+			break
+		}
+		s.iterate(best, s.lastMaxDepth)
+	}
+	after := s.principalVariation(root)
+	changed = len(after) != len(before)
+	for i := 0; i < len(after) && !changed; i++ {
+		changed = after[i] != before[i]
+	}
+	for _, n := range after {
+		pv = append(pv, n.move)
+	}
+	return pv, changed
+}
+
+// principalVariation returns the nodes that the final Move choice follows from n down to a leaf.
+func (s *MCTS) principalVariation(n *treeNode) []*treeNode {
+	var res []*treeNode
+	for len(n.children) > 0 && !n.gameOver {
+		n = s.bestChild(n)
+		res = append(res, n)
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package mcts
+
+import "testing"
+
+func TestSearchContinuation(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if m, _ := s.SearchContinuation(nil, SearchBudget{MaxIters: 10}); m != nil {
+		t.Fatal("expected no continuation without a tree")
+	}
+	s.SearchIterations(newBoard(4, 4), 1, 200)
+	nodes := countNodes(s.root)
+	moves := []Move{&tttMove{i: 0, j: 0, side: 1}, &tttMove{i: 3, j: 3, side: 2}}
+	m, visits := s.SearchContinuation(moves, SearchBudget{MaxIters: 300})
+	if m == nil || visits < 300 {
+		t.Fatalf("expected a searched continuation, got %v with %v visits", m, visits)
+	}
+	mov := m.(*tttMove)
+	if mov.side != 1 || (mov.i == 0 && mov.j == 0) || (mov.i == 3 && mov.j == 3) {
+		t.Fatalf("expected a legal move of player 1, got %+v", mov)
+	}
+	if countNodes(s.root) <= nodes || len(s.root.children) != 16 {
+		t.Fatal("expected the continuation to grow the retained tree")
+	}
+}
+
+func TestAddVisits(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	if m, _ := s.AddVisits(100); m != nil {
+		t.Fatal("expected no move without a tree")
+	}
+	s.SearchIterations(newBoard(4, 4), 1, 100)
+	root := s.root
+	iters, visits := root.iters, root.visits
+	for i := 0; i < 2; i++ {
+		m, v := s.AddVisits(100)
+		if m == nil || v != root.visits {
+			t.Fatalf("expected the best move and the root visits, got %v and %v", m, v)
+		}
+	}
+	if s.root != root || root.iters != iters+200 || root.visits < visits+200 {
+		t.Fatalf("expected 200 more iterations on the same root, got %v more", root.iters-iters)
+	}
+}
+
+func TestExtendPV(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	if pv, _ := s.ExtendPV(100); pv != nil {
+		t.Fatal("expected no principal variation without a tree")
+	}
+	s.SearchIterations(newBoard(4, 4), 1, 300)
+	before := s.principalVariation(s.root)
+	best := before[0]
+	iters := make(map[*treeNode]int64)
+	for _, ch := range s.root.children {
+		iters[ch] = ch.iters
+	}
+	pv, changed := s.ExtendPV(500)
+	if best.iters != iters[best]+500 {
+		t.Fatalf("expected the principal variation to gain 500 iterations, got %v", best.iters-iters[best])
+	}
+	for _, ch := range s.root.children {
+		if ch != best && ch.iters != iters[ch] {
+			t.Fatal("expected the other root moves not to be searched")
+		}
+	}
+	after := s.principalVariation(s.root)
+	if len(pv) != len(after) || pv[0] != best.move {
+		t.Fatalf("expected the principal variation from the best move, got %v moves", len(pv))
+	}
+	same := len(before) == len(after)
+	for i := 0; same && i < len(before); i++ {
+		same = before[i] == after[i]
+	}
+	if changed == same {
+		t.Fatalf("expected the change flag %v for the principal variations", !same)
+	}
+	if len(after) <= 1 {
+		t.Fatalf("expected a deepened principal variation, got %v moves", len(after))
+	}
+}
+
+func TestExtendPVMaxDepth(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.Search(newBoard(4, 4), 1, 0, 2, 300)
+	s.ExtendPV(500)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.depth > 2 {
+			t.Fatalf("expected the extension to keep the MaxDepth of 2, got a node at depth %v", n.depth)
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(s.root)
+}
+
+func TestContinuationAfterMerge(t *testing.T) {
+	var created int64
+	ev := &scratchEval{tttEval: newTTTEval(3, 1), seed: 1, created: &created}
+	s := New(ev, ev)
+	for name, search := range map[string]func(){
+		"parallel": func() { s.SearchParallel(newBoard(3, 3), 1, 2, SearchBudget{MaxIters: 50}) },
+		"determinized": func() {
+			s.SearchDeterminized(func() [][]int { return newBoard(3, 3) }, 2, 1, 0, 0, 50)
+		},
+	} {
+		search()
+		if m, visits := s.SearchContinuation(nil, SearchBudget{MaxIters: 10}); m != nil || visits != 0 {
+			t.Fatalf("expected no continuation of the merged %v root, got %v", name, m)
+		}
+		if m, _ := s.AddVisits(10); m != nil {
+			t.Fatalf("expected no visits to be added to the merged %v root, got %v", name, m)
+		}
+		if pv, _ := s.ExtendPV(10); pv != nil {
+			t.Fatalf("expected no principal variation extension of the merged %v root, got %v", name, pv)
+		}
+	}
+}
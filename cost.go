@@ -0,0 +1,27 @@
+package mcts
+
+// CostReporter is an optional interface that an Evaluator can implement for games whose moves
+// consume a variable amount of a resource, such as time or action points. ApplyMoveCost applies
+// a Move like ApplyMove and also returns its cost. It is used in rollouts instead of ApplyMove,
+// where moves of an Evaluator that does not implement it cost 1.
+type CostReporter interface {
+	ApplyMoveCost(board [][]int, currentPlayerSide int, m Move) (gameOver bool, winner int, cost float64, err error)
+}
+
+// SetMaxPlayoutCost sets the total cost of the moves after which a rollout stops, which is
+// scored as a draw, or with the Scorer if a score mapper is set. Move costs are reported with
+// CostReporter, so for other Evaluators the cost is the number of rollout moves. Only this bound
+// uses the costs: SetDiscount and SetMaxRolloutMoves count plies whatever their moves cost.
+// A cost less than or equal to 0, which is the default, does not bound rollouts.
+func (s *MCTS) SetMaxPlayoutCost(cost float64) {
+	s.maxPlayoutCost = cost
+}
+
+// applyCost applies m to board and returns the result of ApplyMove with the cost of m.
+func (s *MCTS) applyCost(board [][]int, side int, m Move) (bool, int, float64, error) {
+	if cr, ok := s.ev.(CostReporter); ok {
+		return cr.ApplyMoveCost(board, side, m)
+	}
+	gameOver, winner, err := s.ev.ApplyMove(board, side, m)
+	return gameOver, winner, 1, err
+}
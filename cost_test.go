@@ -0,0 +1,72 @@
+package mcts
+
+import "testing"
+
+// costlyEval is a tictactoe evaluator whose moves cost their column plus one.
+type costlyEval struct {
+	*tttEval
+}
+
+func (e *costlyEval) ApplyMoveCost(board [][]int, currentPlayerSide int, m Move) (bool, int, float64, error) {
+	gameOver, winner, err := e.ApplyMove(board, currentPlayerSide, m)
+	return gameOver, winner, float64(m.(*tttMove).j + 1), err
+}
+
+func TestMaxPlayoutCost(t *testing.T) {
+	ev := &costlyEval{tttEval: newTTTEval(5, 1)}
+	s := New(ev, ev)
+	s.SetMaxPlayoutCost(10)
+	s.EnableReplayLog()
+	s.SearchIterations(newBoard(5, 5), 1, 200)
+	for _, e := range s.ReplayLog() {
+		cost := 0.0
+		for i, m := range e.Rollout {
+			if cost >= 10 {
+				t.Fatalf("expected the rollout to stop at the cost cap, move %v of %v follows a cost of %v", i, len(e.Rollout), cost)
+			}
+			cost += float64(m.(*tttMove).j + 1)
+		}
+		if cost < 10 && e.Winner == 0 && len(e.Rollout) > 0 {
+			t.Fatalf("expected a rollout below the cost cap to end the game, got a cost of %v", cost)
+		}
+	}
+}
+
+// resigningEval is a tictactoe evaluator whose side 2 resigns once 2 cells are taken.
+type resigningEval struct {
+	*tttEval
+	calls int
+}
+
+func (e *resigningEval) ShouldResign(board [][]int, side int) (bool, int) {
+	e.calls++
+	taken := 0
+	for _, row := range board {
+		for _, v := range row {
+			if v != 0 {
+				taken++
+			}
+		}
+	}
+	return side == 2 && taken >= 2, 1
+}
+
+func TestResigner(t *testing.T) {
+	ev := &resigningEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	// the tree stays shallow, so every rollout reaches a resignation within a move
+	s.SearchIterations(newBoard(3, 3), 1, 50)
+	if ev.calls == 0 {
+		t.Fatal("expected rollouts to ask whether to resign")
+	}
+	for i, moves := range rs.moves {
+		if rs.winners[i] != 1 {
+			t.Fatalf("expected every rollout to end with the resignation, got winner %v", rs.winners[i])
+		}
+		if len(moves) > 4 {
+			t.Fatalf("expected rollouts to end early, got %v moves", len(moves))
+		}
+	}
+}
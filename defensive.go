@@ -0,0 +1,43 @@
+package mcts
+
+// SetDefensiveSelection sets whether the final move is the root move after which the best reply
+// of the opponent is worst for the opponent, rather than the most visited one. Each root move
+// is scored by the value of its best visited reply, or by its own value if it has none.
+// Replies with fewer visits than set with SetMinTrustVisits are ignored if others are trusted.
+// It does not apply in Minimax backup mode, which already backs up the best replies, and it is
+// overridden by the final Move choices that come before it on Search.
+func (s *MCTS) SetDefensiveSelection(defensive bool) {
+	s.defensive = defensive
+}
+
+// defensiveChild returns the child of n whose best reply has the lowest value.
+func (s *MCTS) defensiveChild(n *treeNode) *treeNode {
+	var res *treeNode
+	var best float64
+	for _, ch := range n.children {
+		v := s.defensiveValue(ch)
+		if res == nil || v > best || (v == best && ch.visits > res.visits) {
+			res, best = ch, v
+		}
+	}
+	if res == nil {
+		panic("could not find any children")
+	}
+	return res
+}
+
+// defensiveValue returns the value of n from the perspective of n.side after the best reply.
+func (s *MCTS) defensiveValue(n *treeNode) float64 {
+	for _, minVisits := range []int64{s.minTrustVisits, 1} {
+		var reply *treeNode
+		for _, ch := range n.children {
+			if ch.visits >= minVisits && ch.visits > 0 && (reply == nil || ch.value() > reply.value()) {
+				reply = ch
+			}
+		}
+		if reply != nil {
+			return -reply.value()
+		}
+	}
+	return n.value()
+}
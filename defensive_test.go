@@ -0,0 +1,37 @@
+package mcts
+
+import "testing"
+
+func TestDefensiveSelection(t *testing.T) {
+	// a is visited most, but one reply to it is strong for the opponent
+	root := &treeNode{side: 2, toMove: 1, visits: 150}
+	a := &treeNode{parent: root, side: 1, depth: 1, visits: 100, winScore: 50}
+	b := &treeNode{parent: root, side: 1, depth: 1, visits: 50, winScore: 10}
+	root.children = []*treeNode{a, b}
+	for _, reply := range []struct {
+		parent   *treeNode
+		visits   int64
+		winScore float64
+	}{{a, 10, 9}, {a, 89, -53}, {b, 25, 2.5}, {b, 24, -12}} {
+		reply.parent.children = append(reply.parent.children, &treeNode{
+			parent:   reply.parent,
+			side:     2,
+			depth:    2,
+			visits:   reply.visits,
+			winScore: reply.winScore,
+		})
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if s.bestChild(root) != a {
+		t.Fatal("expected the most visited move by default")
+	}
+	s.SetDefensiveSelection(true)
+	if s.bestChild(root) != b {
+		t.Fatal("expected defensive selection to avoid the move with a strong reply")
+	}
+	s.SetMinTrustVisits(20)
+	if s.bestChild(root) != a {
+		t.Fatal("expected the untrusted strong reply to be ignored")
+	}
+}
@@ -0,0 +1,78 @@
+package mcts
+
+import "time"
+
+// SearchDeterminized searches a position of an imperfect information game by sampling
+// boards that are consistent with what side knows. sampler is called samples times and each
+// sampled board is searched in its own tree with the given caps. The root Move statistics of
+// all trees are then combined by Move, and the Move with the most combined visits is returned
+// together with the combined number of visits.
+func (s *MCTS) SearchDeterminized(sampler func() [][]int, samples int, side int, duration time.Duration, maxDepth, maxIters int) (Move, int64) {
+	roots := make([]*treeNode, 0, samples)
+	reuse, recorder := s.reuseTree, s.recorder
+	s.reuseTree, s.recorder = false, nil
+	var rollouts, terminals int64
+	for i := 0; i < samples; i++ {
+		// the warm start priors only apply to the first sample, so they are counted once
+		roots = append(roots, s.search(sampler(), side, SearchBudget{Duration: duration, MaxDepth: maxDepth, MaxIters: maxIters}))
+		rollouts += s.rollouts
+		terminals += s.terminals
+	}
+	s.reuseTree, s.recorder = reuse, recorder
+	s.warm = nil
+	s.rollouts, s.terminals = rollouts, terminals
+	root := s.mergeRoots(roots)
+	s.root = root
+	if len(root.children) == 0 {
+		return nil, root.visits
+	}
+	return s.bestChild(root).move, root.visits
+}
+
+// merged reports whether n is a root built by mergeRoots, which has no board to search from.
+func merged(n *treeNode) bool {
+	return n.parent == nil && n.board == nil
+}
+
+// mergeRoots returns a root whose children combine the statistics of the children of roots
+// that have equal Moves. The merged root has no board and its children have no subtrees.
+func (s *MCTS) mergeRoots(roots []*treeNode) *treeNode {
+	res := &treeNode{children: make([]*treeNode, 0)}
+	for _, r := range roots {
+		res.side = r.side
+		res.visits += r.visits
+		res.winScore += r.winScore
+		res.sqScore += r.sqScore
+		res.unweighted += r.unweighted
+		res.iters += r.iters
+		res.elapsed += r.elapsed
+		res.plySum += r.plySum
+		for _, ch := range r.children {
+			var merged *treeNode
+			for _, m := range res.children {
+				if s.moveEqual(m.move, ch.move) {
+					merged = m
+					break
+				}
+			}
+			if merged == nil {
+				merged = &treeNode{
+					children: make([]*treeNode, 0),
+					depth:    1,
+					move:     ch.move,
+					parent:   res,
+					side:     ch.side,
+				}
+				res.children = append(res.children, merged)
+			}
+			merged.visits += ch.visits
+			merged.winScore += ch.winScore
+			merged.sqScore += ch.sqScore
+			merged.unweighted += ch.unweighted
+			merged.iters += ch.iters
+			merged.elapsed += ch.elapsed
+			merged.plySum += ch.plySum
+		}
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package mcts
+
+import "testing"
+
+func TestSearchDeterminized(t *testing.T) {
+	// X wins at (0, 2) in both worlds, while (2, 2) only wins in the first one
+	// and loses in the second one.
+	worlds := [][][]int{
+		{
+			{1, 1, 0},
+			{2, 1, 0},
+			{2, 2, 0},
+		},
+		{
+			{1, 1, 0},
+			{2, 2, 1},
+			{2, 0, 0},
+		},
+	}
+	i := 0
+	sampler := func() [][]int {
+		w := copyBoard(worlds[i%len(worlds)])
+		i++
+		return w
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	m, visits := s.SearchDeterminized(sampler, 4, 1, 0, 0, 100)
+	if mov := m.(*tttMove); mov.i != 0 || mov.j != 2 {
+		t.Fatalf("expected the robust move (0, 2), got (%v, %v)", mov.i, mov.j)
+	}
+	if i != 4 {
+		t.Fatalf("expected 4 samples, got %v", i)
+	}
+	var sum int64
+	for _, st := range s.TopMoves(10) {
+		sum += st.Visits
+	}
+	// every expansion of a root child adds a visit to the root as well
+	if sum > visits {
+		t.Fatalf("combined child visits %v exceed root visits %v", sum, visits)
+	}
+	if len(s.TopMoves(10)) != 4 {
+		t.Fatalf("expected 4 distinct root moves across the worlds, got %v", len(s.TopMoves(10)))
+	}
+}
@@ -0,0 +1,36 @@
+package mcts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// ResultDigest returns a hash of the root statistics of the last search, which are the Move,
+// visits, value and proof of every root child in expansion order, and the root visits. Moves are
+// formatted with %+v, so Moves that would print an address, like pointers to types other than
+// structs, should implement fmt.Stringer. After a deterministic search, for example with seeded
+// random number generators and an iteration budget, the digest is the same on every run, so
+// that tests can detect changes of behavior. It returns 0 if there is no root.
+func (s *MCTS) ResultDigest() uint64 {
+	if s.root == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	var buf [8]byte
+	write := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	write(uint64(s.root.visits))
+	for _, ch := range s.root.children {
+		fmt.Fprintf(h, "%+v", ch.move)
+		write(uint64(ch.visits))
+		write(math.Float64bits(ch.value()))
+		if ch.proven {
+			write(uint64(ch.provenWinner))
+		}
+	}
+	return h.Sum64()
+}
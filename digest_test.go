@@ -0,0 +1,22 @@
+package mcts
+
+import "testing"
+
+func TestResultDigest(t *testing.T) {
+	digest := func(seed int64, iters int) uint64 {
+		ev := newTTTEval(4, seed)
+		s := New(ev, ev)
+		s.SearchIterations(newBoard(4, 4), 1, iters)
+		return s.ResultDigest()
+	}
+	if New(nil, nil).ResultDigest() != 0 {
+		t.Fatal("expected a zero digest without a search")
+	}
+	d := digest(1, 500)
+	if d == 0 || d != digest(1, 500) {
+		t.Fatal("expected the same digest with the same seed")
+	}
+	if d == digest(2, 500) || d == digest(1, 501) {
+		t.Fatal("expected a different digest for a different search")
+	}
+}
@@ -0,0 +1,41 @@
+package mcts
+
+// SetResolveDisagreement sets the maximum number of extra iterations a search runs when the most
+// visited root Move is not the Move with the highest value, which happens when the budget ends
+// before the visits have caught up with a better Move. The extra iterations are focused on the
+// highest value Move until it either becomes the most visited one or its value drops below the
+// value of the most visited one. It does not apply in Minimax backup mode, which chooses by value.
+// The default of 0 runs no extra iterations.
+func (s *MCTS) SetResolveDisagreement(maxExtraIters int) {
+	s.resolveExtra = maxExtraIters
+}
+
+// disputedChildren returns the child that the final Move choice picks, which is the most visited
+// child unless another choice is configured, and the highest value child of n, ignoring children
+// with fewer visits than the trust threshold for the value.
+func (s *MCTS) disputedChildren(n *treeNode) (visited, valued *treeNode) {
+	visited = s.bestChild(n)
+	valued = visited
+	for _, ch := range n.children {
+		if ch.visits >= s.minTrustVisits && ch.value() > valued.value() {
+			valued = ch
+		}
+	}
+	return visited, valued
+}
+
+// resolveDisagreement runs extra iterations from the highest value child of root while it is
+// not the most visited child.
+func (s *MCTS) resolveDisagreement(root *treeNode, maxDepth int) {
+	if s.backupMode == Minimax || len(root.children) == 0 {
+		return
+	}
+	for i := 0; i < s.resolveExtra; i++ {
+		s.pause.wait()
+		visited, valued := s.disputedChildren(root)
+		if visited == valued {
+			return
+		}
+		s.iterate(valued, maxDepth)
+	}
+}
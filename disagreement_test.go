@@ -0,0 +1,41 @@
+package mcts
+
+import "testing"
+
+func TestResolveDisagreement(t *testing.T) {
+	search := func(iters, extra int) *MCTS {
+		ev := newTTTEval(4, 1)
+		s := New(ev, ev)
+		s.SetResolveDisagreement(extra)
+		s.SearchIterations(newBoard(4, 4), 1, iters)
+		return s
+	}
+	// find a budget whose search ends with a disagreement
+	iters := 0
+	for i := 20; i < 200 && iters == 0; i++ {
+		s := search(i, 0)
+		if visited, valued := s.disputedChildren(s.root); visited != valued {
+			iters = i
+		}
+	}
+	if iters == 0 {
+		t.Fatal("expected a disagreement for a small budget")
+	}
+	s := search(iters, 1000)
+	if s.rollouts <= int64(iters) {
+		t.Fatal("expected extra iterations")
+	}
+	if visited, valued := s.disputedChildren(s.root); visited != valued {
+		t.Fatal("expected the disagreement to be resolved")
+	}
+
+	// a final Move choice by value never disagrees with the highest value
+	ev := newTTTEval(4, 1)
+	s = New(ev, ev)
+	s.SetFinalRanker(ByValue)
+	s.SetResolveDisagreement(1000)
+	s.SearchIterations(newBoard(4, 4), 1, iters)
+	if s.rollouts != int64(iters) {
+		t.Fatalf("expected no extra iterations when choosing by value, got %v rollouts", s.rollouts)
+	}
+}
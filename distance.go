@@ -0,0 +1,49 @@
+package mcts
+
+import "math"
+
+// SetDistanceTiebreak sets the tolerance within which the final Move is chosen by the distance
+// to the end of the game instead of by the backup mode. Among the children whose value is within
+// tolerance of the value of the chosen child, a winning position, whose value is above 0, plays
+// the child with the fewest expected plies to the end of the game and a losing one plays the
+// child with the most, which gives the opponent more chances to err.
+// A tolerance less than or equal to 0, which is the default, disables the tiebreak.
+func (s *MCTS) SetDistanceTiebreak(tolerance float64) {
+	s.distanceTolerance = tolerance
+}
+
+// finalValue returns the value of n used to choose the final move.
+func (s *MCTS) finalValue(n *treeNode) float64 {
+	if s.backupMode == Minimax {
+		return n.minimaxValue()
+	}
+	return n.value()
+}
+
+// terminalDistance returns the mean number of plies from n to the end of the game in the
+// iterations whose rollout started in its subtree.
+func (n *treeNode) terminalDistance() float64 {
+	if n.iters == 0 {
+		return 0
+	}
+	return float64(n.plySum) / float64(n.iters)
+}
+
+// distanceTiebreak returns the child of n that the distance tiebreak chooses over best.
+func (s *MCTS) distanceTiebreak(n, best *treeNode) *treeNode {
+	v := s.finalValue(best)
+	if v == 0 {
+		return best
+	}
+	res := best
+	for _, ch := range n.children {
+		if ch.iters == 0 || ch.visits < s.minTrustVisits || math.Abs(s.finalValue(ch)-v) > s.distanceTolerance {
+			continue
+		}
+		d, rd := ch.terminalDistance(), res.terminalDistance()
+		if (v > 0 && d < rd) || (v < 0 && d > rd) {
+			res = ch
+		}
+	}
+	return res
+}
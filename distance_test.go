@@ -0,0 +1,57 @@
+package mcts
+
+import "testing"
+
+func TestDistanceTiebreak(t *testing.T) {
+	// player 1 wins at once at (0, 2), while (0, 0), (1, 0) and (1, 2) win two plies later
+	board := [][]int{
+		{0, 0, 0},
+		{0, 1, 0},
+		{1, 2, 2},
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetBackupMode(Minimax)
+	s.SetKeepSearchingAfterProof(true)
+	s.SetDistanceTiebreak(0.01)
+	m, _ := s.SearchIterations(board, 1, 5000)
+	if mov := m.(*tttMove); mov.i != 0 || mov.j != 2 {
+		t.Fatalf("expected the immediate win at (0, 2), got (%v, %v)", mov.i, mov.j)
+	}
+	var fast, slow *treeNode
+	for _, ch := range s.root.children {
+		mov := ch.move.(*tttMove)
+		switch {
+		case mov.i == 0 && mov.j == 2:
+			fast = ch
+		case mov.i == 1 && mov.j == 0:
+			slow = ch
+		}
+	}
+	if fast.minimaxValue() != 1 || slow.minimaxValue() != 1 {
+		t.Fatalf("expected two winning moves, got %v and %v", fast.minimaxValue(), slow.minimaxValue())
+	}
+	if fast.terminalDistance() != 0 || slow.terminalDistance() < 2 {
+		t.Fatalf("expected distances of 0 and at least 2, got %v and %v", fast.terminalDistance(), slow.terminalDistance())
+	}
+}
+
+func TestDistanceTiebreakChoice(t *testing.T) {
+	root := &treeNode{side: 2}
+	long := &treeNode{parent: root, side: 1, visits: 10, winScore: -10, iters: 10, plySum: 50}
+	short := &treeNode{parent: root, side: 1, visits: 20, winScore: -20, iters: 20, plySum: 20}
+	root.children = []*treeNode{short, long}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if s.bestChild(root) != short {
+		t.Fatal("expected the most visited move without the tiebreak")
+	}
+	s.SetDistanceTiebreak(0.01)
+	if s.bestChild(root) != long {
+		t.Fatal("expected the longest resistance when losing")
+	}
+	long.winScore, short.winScore = 10, 20
+	if s.bestChild(root) != short {
+		t.Fatal("expected the quickest win when winning")
+	}
+}
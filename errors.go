@@ -0,0 +1,66 @@
+package mcts
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoLegalMoves is returned when the searched board is already a finished game or the side
+// to move has no Move.
+var ErrNoLegalMoves = errors.New("mcts: no legal moves")
+
+// ErrInvalidSide is returned when the searched side is not handled consistently by the
+// Evaluator, which means that PrevPlayer is not the inverse of NextPlayer for it.
+var ErrInvalidSide = errors.New("mcts: invalid side")
+
+// ErrUnknownMove is returned when a Move is not a root move of the last search.
+var ErrUnknownMove = errors.New("mcts: unknown move")
+
+// ErrIllegalMove is wrapped by the error of a rollout Move that is not returned by Expand,
+// which is only checked with SetDebugChecks.
+var ErrIllegalMove = errors.New("mcts: illegal move")
+
+// ErrInvalidTreeData is returned when the data read by UnmarshalTreeBinary is not a tree
+// written by MarshalTreeBinary.
+var ErrInvalidTreeData = errors.New("mcts: invalid binary tree data")
+
+// ErrEvaluator is returned when ApplyMove fails. Phase is "expand" if the Move was applied to
+// create a tree node, "rollout" if it was applied during a rollout, "validate" if it was
+// applied by Validate, "match" if it was played by PlayMatch and "solve" if it was applied by
+// SolveExact, which panics with it. Err is the error returned by ApplyMove.
+type ErrEvaluator struct {
+	Phase string
+	Err   error
+}
+
+func (e *ErrEvaluator) Error() string {
+	return fmt.Sprintf("mcts: evaluator failed during %v: %v", e.Phase, e.Err)
+}
+
+func (e *ErrEvaluator) Unwrap() error {
+	return e.Err
+}
+
+// SearchE searches like SearchWithBudget, but returns an error instead of panicking when
+// ApplyMove or a debug check fails and instead of returning a nil Move when there is no Move to play.
+// A tree that was being built when ApplyMove failed is not reused.
+func (s *MCTS) SearchE(board [][]int, side int, b SearchBudget) (m Move, visits int64, err error) {
+	if s.ev.PrevPlayer(s.ev.NextPlayer(side)) != side {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidSide, side)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*ErrEvaluator)
+			if !ok {
+				panic(r)
+			}
+			s.root = nil
+			m, visits, err = nil, 0, e
+		}
+	}()
+	m, visits = s.SearchWithBudget(board, side, b)
+	if m == nil {
+		return nil, visits, ErrNoLegalMoves
+	}
+	return m, visits, nil
+}
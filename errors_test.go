@@ -0,0 +1,64 @@
+package mcts
+
+import (
+	"errors"
+	"testing"
+)
+
+// rolloutRejectingEval returns random moves that ApplyMove rejects, so only rollouts fail.
+type rolloutRejectingEval struct {
+	*tttEval
+}
+
+func (e *rolloutRejectingEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return &tttMove{i: -1, j: -1, side: currentPlayerSide}
+}
+
+func (e *rolloutRejectingEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	if m.(*tttMove).i < 0 {
+		return false, 0, errors.New("illegal move")
+	}
+	return e.tttEval.ApplyMove(board, currentPlayerSide, m)
+}
+
+func TestSearchEErrors(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	b := SearchBudget{MaxIters: 10}
+	for phase, e := range map[string]Evaluator{
+		"expand":  &rejectingEval{ev},
+		"rollout": &rolloutRejectingEval{ev},
+	} {
+		_, _, err := New(e, ev).SearchE(newBoard(3, 3), 1, b)
+		var evErr *ErrEvaluator
+		if !errors.As(err, &evErr) || evErr.Phase != phase {
+			t.Fatalf("expected an evaluator error during %v, got %v", phase, err)
+		}
+		if evErr.Err == nil || evErr.Err.Error() != "illegal move" {
+			t.Fatalf("expected the ApplyMove error to be wrapped, got %v", evErr.Err)
+		}
+	}
+
+	board := newBoard(3, 3)
+	board[0][0], board[0][1], board[0][2] = 1, 1, 1
+	tev := &terminalEval{tttEval: ev}
+	if _, _, err := New(tev, tev).SearchE(board, 2, b); !errors.Is(err, ErrNoLegalMoves) {
+		t.Fatalf("expected no legal moves, got %v", err)
+	}
+	if _, _, err := New(&brokenPlayers{ev}, ev).SearchE(newBoard(3, 3), 1, b); !errors.Is(err, ErrInvalidSide) {
+		t.Fatalf("expected an invalid side, got %v", err)
+	}
+	if m, _, err := New(ev, ev).SearchE(newBoard(3, 3), 1, b); m == nil || err != nil {
+		t.Fatalf("expected a move, got %v %v", m, err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	var evErr *ErrEvaluator
+	if err := New(&rejectingEval{ev}, ev).Validate(newBoard(3, 3), 1); !errors.As(err, &evErr) || evErr.Phase != "validate" {
+		t.Fatalf("expected an evaluator error during validate, got %v", err)
+	}
+	if err := New(&brokenPlayers{ev}, ev).Validate(newBoard(3, 3), 1); !errors.Is(err, ErrInvalidSide) {
+		t.Fatalf("expected an invalid side, got %v", err)
+	}
+}
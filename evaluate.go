@@ -0,0 +1,28 @@
+package mcts
+
+// Evaluate searches board like SearchWithBudget, but returns the estimated value of the position
+// for side instead of a Move, which is useful when the search evaluates the leaves of a larger
+// search. value is the mean rollout reward of the root from the perspective of side, which
+// unlike the value of a node does not include the evaluations of expanded Moves, and dist holds
+// the fraction of rollouts won by each side, with draws under the draw sentinel.
+// If board is already a finished game, dist holds only its winner and value is its reward for side.
+// The same holds for the proven outcome of a reused root that needs no rollouts, and a search
+// without rollouts otherwise returns the value of the root and an empty dist.
+// value is calibrated if SetValueCalibration is set.
+func (s *MCTS) Evaluate(board [][]int, side int, b SearchBudget) (value float64, dist map[int]float64) {
+	root := s.search(board, side, b)
+	switch {
+	case root.gameOver:
+		value, dist = s.sign(side, root.winner), map[int]float64{root.winner: 1}
+	case s.rollouts == 0 && root.proven:
+		value, dist = s.sign(side, root.provenWinner), map[int]float64{root.provenWinner: 1}
+	case s.rollouts == 0:
+		value, dist = root.valueFor(side), map[int]float64{}
+	default:
+		value, dist = s.rolloutScore/float64(s.rollouts), s.RolloutBalance()
+	}
+	if s.calibration > 0 {
+		value = s.calibrated(value)
+	}
+	return value, dist
+}
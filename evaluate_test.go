@@ -0,0 +1,63 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	// random games of five in a row on a 5x5 board are mostly drawn and nearly fair
+	ev := newTTTEval(5, 1)
+	s := New(ev, ev)
+	value, dist := s.Evaluate(newBoard(5, 5), 1, SearchBudget{MaxIters: 2000})
+	if value < -0.15 || value > 0.15 {
+		t.Fatalf("expected a value near 0, got %v", value)
+	}
+	if dist[0] < 0.5 {
+		t.Fatalf("expected mostly draws, got %v", dist)
+	}
+	total := 0.0
+	for _, p := range dist {
+		total += p
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Fatalf("expected the distribution to sum to 1, got %v", total)
+	}
+}
+
+func TestEvaluateTerminal(t *testing.T) {
+	ev := &terminalEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	board := newBoard(3, 3)
+	board[0][0], board[0][1], board[0][2] = 2, 2, 2
+	value, dist := s.Evaluate(board, 1, SearchBudget{MaxIters: 10})
+	if value != -1 || dist[2] != 1 {
+		t.Fatalf("expected a lost position, got %v %v", value, dist)
+	}
+}
+
+func TestEvaluateRolloutMean(t *testing.T) {
+	// every game is won by player 1, while the expansion visits of the root children count 0
+	g := &marginGame{r: rand.New(rand.NewSource(1))}
+	s := New(g, g)
+	if v, _ := s.Evaluate(newBoard(1, 2), 1, SearchBudget{MaxIters: 50}); v != 1 {
+		t.Fatalf("expected the mean rollout reward of 1, got %v", v)
+	}
+}
+
+func TestEvaluateReusedProvenRoot(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	// X wins at (0, 2).
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	s.Evaluate(board, 1, SearchBudget{MaxIters: 300})
+	value, dist := s.Evaluate(board, 1, SearchBudget{MaxIters: 300})
+	if s.rollouts != 0 || value != 1 || dist[1] != 1 {
+		t.Fatalf("expected the proven win of the reused root without rollouts, got %v %v after %v rollouts", value, dist, s.rollouts)
+	}
+}
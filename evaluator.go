@@ -11,3 +11,59 @@ type Evaluator interface {
 	NextPlayer(currentPlayerSide int) int
 	PrevPlayer(currentPlayerSide int) int
 }
+
+// StateCloner is an optional interface that an Evaluator can implement to copy boards
+// in a game specific way, for example to preserve metadata encoded in extra rows.
+// When implemented, CloneState is used instead of the default deep copy.
+type StateCloner interface {
+	CloneState(board [][]int) [][]int
+}
+
+// TerminalChecker is an optional interface that an Evaluator can implement to report whether
+// a board is a finished game without applying a move. winner is the draw sentinel for a draw.
+type TerminalChecker interface {
+	IsTerminal(board [][]int) (gameOver bool, winner int)
+}
+
+// GameOverReason is the reason why a game is over at a terminal node. Evaluators can define
+// their own reasons after ReasonDoublePass.
+type GameOverReason int
+
+const (
+	// ReasonUnknown is the reason of nodes that are not terminal, and of terminal nodes other
+	// than double passes if the Evaluator does not implement GameOverReasoner.
+	ReasonUnknown GameOverReason = iota
+	// ReasonWin is a game won by the winner.
+	ReasonWin
+	// ReasonNoMoves is a game that ended because no side can move, like a full board.
+	ReasonNoMoves
+	// ReasonRepetition is a game that ended by a repeated position.
+	ReasonRepetition
+	// ReasonResignation is a game that a side resigned.
+	ReasonResignation
+	// ReasonDoublePass is a game that ended by two consecutive passes, which the search detects
+	// itself.
+	ReasonDoublePass
+)
+
+// GameOverReasoner is an optional interface that an Evaluator can implement to report why a
+// game is over. GameOverReason is called for every terminal tree node with its board and winner
+// and its result is reported by NodeView and ChildStat.
+type GameOverReasoner interface {
+	GameOverReason(board [][]int, winner int) GameOverReason
+}
+
+// Resigner is an optional interface that an Evaluator can implement to end hopeless rollouts
+// early, like resignation thresholds in self-play. ShouldResign is called in rollouts before
+// side moves on board, and if resign is true the rollout ends with winner as its outcome.
+type Resigner interface {
+	ShouldResign(board [][]int, side int) (resign bool, winner int)
+}
+
+// Passer is an optional interface that an Evaluator can implement for games that allow
+// passing. PassMove returns the pass Move of side, which is compared to other Moves with the
+// Move equality of the search. Two consecutive passes end the game as a draw, both in the
+// tree and in rollouts. Games that are scored after passing can use a Scorer to score the final board.
+type Passer interface {
+	PassMove(side int) Move
+}
@@ -0,0 +1,69 @@
+package mcts
+
+// SolveExact returns the best Move of side on board and the outcome of the game with best play,
+// found by an exhaustive negamax search of a two player game with ApplyMove and Expand. It is
+// only practical for tiny games, for example as an oracle to validate search results. Lines that
+// are not finished within maxDepth moves are scored like draws, and a maxDepth less than or equal
+// to 0 searches every line to its end. The outcome is the winner, or the draw sentinel for a draw.
+// If board is already a finished game, SolveExact returns a nil Move and its winner.
+func (s *MCTS) SolveExact(board [][]int, side, maxDepth int) (Move, int) {
+	board = cloneBoard(s.ev, board)
+	if gameOver, winner := s.terminalRoot(board, side); gameOver {
+		return nil, winner
+	}
+	m, _, winner := s.solve(board, side, nil, 0, maxDepth)
+	return m, winner
+}
+
+// solve returns the best Move of side on board, its value for side and the winner with best
+// play. last is the Move played by the previous side to detect consecutive passes, and depth the
+// number of moves played since the searched board.
+func (s *MCTS) solve(board [][]int, side int, last Move, depth, maxDepth int) (Move, float64, int) {
+	if maxDepth > 0 && depth >= maxDepth {
+		return nil, 0, s.draw
+	}
+	moves := s.expandAt(board, side, depth)
+	if len(moves) == 0 {
+		return nil, 0, s.draw
+	}
+	var best Move
+	bestValue, bestWinner := -2.0, s.draw
+	for _, m := range moves {
+		child := cloneBoard(s.ev, board)
+		gameOver, winner, err := s.ev.ApplyMove(child, side, m)
+		if err != nil {
+			panic(&ErrEvaluator{Phase: "solve", Err: err})
+		}
+		if !gameOver && s.isPass(m, side) && s.isPass(last, s.ev.PrevPlayer(side)) {
+			// two consecutive passes end the game as a draw
+			gameOver, winner = true, s.draw
+		}
+		var value float64
+		if gameOver {
+			value = s.outcomeValue(side, winner)
+		} else {
+			_, v, w := s.solve(child, s.ev.NextPlayer(side), m, depth+1, maxDepth)
+			value, winner = -v, w
+		}
+		if value > bestValue {
+			best, bestValue, bestWinner = m, value, winner
+			if value == 1 {
+				// a win can not be improved on
+				break
+			}
+		}
+	}
+	return best, bestValue, bestWinner
+}
+
+// outcomeValue returns 1 if side is winner, 0 for a draw and -1 otherwise.
+func (s *MCTS) outcomeValue(side, winner int) float64 {
+	switch winner {
+	case s.draw:
+		return 0
+	case side:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,38 @@
+package mcts
+
+import "testing"
+
+func TestSolveExact(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if _, winner := s.SolveExact(newBoard(3, 3), 1, 0); winner != 0 {
+		t.Fatalf("expected tictactoe to be a draw, got winner %v", winner)
+	}
+	for _, tc := range []struct {
+		board  [][]int
+		winner int
+	}{
+		{[][]int{{1, 2, 0}, {0, 1, 0}, {0, 0, 2}}, 1},
+		{[][]int{{0, 0, 0}, {0, 2, 0}, {1, 1, 2}}, 0},
+		{[][]int{{1, 0, 0}, {2, 2, 0}, {1, 0, 0}}, 0},
+	} {
+		exact, winner := s.SolveExact(tc.board, 1, 0)
+		if exact == nil || winner != tc.winner {
+			t.Fatalf("expected winner %v for %v, got %v", tc.winner, tc.board, winner)
+		}
+		m, _ := New(ev, ev).SearchIterations(copyBoard(tc.board), 1, 20000)
+		// the search agrees if its move keeps the exact outcome
+		board := copyBoard(tc.board)
+		ev.ApplyMove(board, 1, m)
+		if _, w := s.SolveExact(board, 2, 0); w != winner {
+			t.Fatalf("expected the searched move %v to keep outcome %v, got %v", m, winner, w)
+		}
+	}
+	tev := &terminalEval{tttEval: ev}
+	if _, winner := New(tev, tev).SolveExact([][]int{{1, 1, 1}, {2, 2, 0}, {0, 0, 0}}, 2, 0); winner != 1 {
+		t.Fatalf("expected the winner of a finished game, got %v", winner)
+	}
+	if _, winner := s.SolveExact(newBoard(3, 3), 1, 2); winner != 0 {
+		t.Fatalf("expected unfinished lines to be scored as draws, got %v", winner)
+	}
+}
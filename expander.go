@@ -1,7 +1,74 @@
 package mcts
 
 // Expander should be able to return a list possible preferably legal moves to add to the tree as leaves given
-// a board and current side.
+// a board and current side. An Expander that prunes legal moves requires the solver to be disabled,
+// see SetSolver.
 type Expander interface {
 	Expand(board [][]int, side int) []Move
 }
+
+// MoveCountHinter is an optional interface that an Expander can implement for games with
+// a known maximum branching factor. When implemented, expansion preallocates children with
+// a capacity of MaxMoves and allocates all children of a node in a single contiguous block.
+// Siblings then stay in memory as long as any of them is referenced, for example by a reused tree.
+type MoveCountHinter interface {
+	MaxMoves() int
+}
+
+// DepthExpander is an optional interface that an Expander can implement for depth dependent
+// move generation, for example to generate only captures deep in the tree. When implemented,
+// ExpandAt is used instead of Expand to expand tree nodes, where depth is the depth of the
+// expanded node and the root is at depth 0.
+type DepthExpander interface {
+	ExpandAt(board [][]int, side, depth int) []Move
+}
+
+// MoveIterator yields Moves one at a time. Next returns the next Move, or false once there are
+// no more Moves.
+type MoveIterator interface {
+	Next() (Move, bool)
+}
+
+// LazyExpander is an optional interface that an Expander can implement for games with huge
+// move sets. With progressive widening or unpruning, ExpandLazy is used instead of Expand to
+// expand tree nodes, and Moves are only pulled from the returned iterator when widening adds a
+// child, so a node only generates as many Moves as it gets children. The iterator should yield
+// Moves in priority order, since they are not ranked by Eval, and it may keep board, which is not
+// modified while the node is in the tree. Moves equal to a child of the node are skipped.
+// ExpandLazy also takes precedence over ExpandAt, so an Expander implementing both must generate
+// depth dependent Moves in its iterators itself.
+type LazyExpander interface {
+	ExpandLazy(board [][]int, side int) MoveIterator
+}
+
+// MoveFilter reports whether the Move m may be added to the tree as a node at depth, where the
+// root children are at depth 1.
+type MoveFilter func(m Move, depth int) bool
+
+// SetMoveFilter sets a filter of the Moves that expansion adds to the tree, which restricts the
+// search to the allowed subtree, for example to the moves in a region of the board. Rollouts
+// still play any Move. A node whose Moves are all filtered out stays a leaf. A node with filtered
+// Moves is proven by a winning child, but not by the outcomes of its remaining children, since a
+// filtered Move could be better. A nil filter, which is the default, allows every Move.
+func (s *MCTS) SetMoveFilter(f MoveFilter) {
+	s.moveFilter = f
+}
+
+// expandAt returns the Moves of side on board for a node at depth.
+func (s *MCTS) expandAt(board [][]int, side, depth int) []Move {
+	if de, ok := s.ex.(DepthExpander); ok {
+		return de.ExpandAt(board, side, depth)
+	}
+	return s.ex.Expand(board, side)
+}
+
+// filterMoves returns the Moves of a node at depth that the move filter allows.
+func (s *MCTS) filterMoves(moves []Move, depth int) []Move {
+	res := moves[:0:0]
+	for _, m := range moves {
+		if s.moveFilter(m, depth+1) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package mcts
+
+import "math"
+
+// MoveExplanation describes a root move of the last search.
+// Visits and Value are the statistics of the move like in ChildStat, UCB is its UCB value when
+// the search ended, which is +Inf for an unvisited move, and Rank is its 1 based position among
+// the Siblings root moves in the order of TopMoves.
+type MoveExplanation struct {
+	Move     Move
+	Visits   int64
+	Value    float64
+	UCB      float64
+	Rank     int
+	Siblings int
+}
+
+// ExplainMove returns the statistics of the root move m of the last search, which helps to
+// understand why m was not chosen. Moves are compared with the function set with SetMoveEqual.
+// It returns ErrUnknownMove if there was no search or m is not a root move.
+func (s *MCTS) ExplainMove(m Move) (MoveExplanation, error) {
+	if s.root == nil {
+		return MoveExplanation{}, ErrUnknownMove
+	}
+	idx := -1
+	for i, ch := range s.root.children {
+		if s.moveEqual(ch.move, m) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return MoveExplanation{}, ErrUnknownMove
+	}
+	node := s.root.children[idx]
+	res := MoveExplanation{
+		Move:     node.move,
+		Visits:   node.visits,
+		Value:    node.value(),
+		UCB:      math.Inf(1),
+		Rank:     1,
+		Siblings: len(s.root.children),
+	}
+	if node.visits > 0 {
+		mean, inv := s.ucbTerms(node)
+		res.UCB = mean + s.explorationTerm(s.root)*inv
+	}
+	for i, ch := range s.root.children {
+		// ties are ranked like the stable sort of TopMoves
+		if ch.visits > node.visits || ch.visits == node.visits &&
+			(ch.value() > node.value() || ch.value() == node.value() && i < idx) {
+			res.Rank++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,37 @@
+package mcts
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestExplainMove(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if _, err := s.ExplainMove(&tttMove{i: 1, j: 1}); !errors.Is(err, ErrUnknownMove) {
+		t.Fatalf("expected ErrUnknownMove before a search, got %v", err)
+	}
+	s.SearchIterations(newBoard(3, 3), 1, 2000)
+	top := s.TopMoves(2)
+	best, err := s.ExplainMove(top[0].Move)
+	if err != nil || best.Rank != 1 || best.Siblings != 9 {
+		t.Fatalf("expected the best move to rank first of 9, got %+v and %v", best, err)
+	}
+	second, err := s.ExplainMove(top[1].Move)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Rank != 2 || second.Visits != top[1].Visits {
+		t.Fatalf("expected the second move to rank second, got %+v", second)
+	}
+	if second.Value >= best.Value {
+		t.Fatalf("expected the second move to have a lower value than %v, got %v", best.Value, second.Value)
+	}
+	if math.IsInf(second.UCB, 0) || second.UCB <= second.Value {
+		t.Fatalf("expected a finite UCB value above the mean, got %v", second.UCB)
+	}
+	if _, err := s.ExplainMove(&tttMove{i: 5, j: 5}); !errors.Is(err, ErrUnknownMove) {
+		t.Fatalf("expected ErrUnknownMove, got %v", err)
+	}
+}
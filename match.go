@@ -0,0 +1,76 @@
+package mcts
+
+// MatchResult holds the outcome of a match between two searches.
+type MatchResult struct {
+	AWins int
+	BWins int
+	Draws int
+}
+
+// PlayMatch plays games between the searches a and b and returns the result from the
+// perspective of a. a moves first with startSide in even games and b in odd games, so colors
+// alternate. Every game starts from newBoard and each Move is searched with Search without caps,
+// so the budget of a move is set with SetDefaultIterations. Moves are applied with the Evaluator
+// of a, which both searches should share, and a failing ApplyMove panics with an ErrEvaluator
+// whose Phase is "match". Only games of two sides, which NextPlayer alternates between, can be
+// played, and PlayMatch panics otherwise. A winner that is neither side counts as a draw.
+func PlayMatch(a, b *MCTS, newBoard func() [][]int, startSide int, games int) MatchResult {
+	other := a.ev.NextPlayer(startSide)
+	if other == startSide || a.ev.NextPlayer(other) != startSide {
+		panic("PlayMatch requires a game of two sides")
+	}
+	var res MatchResult
+	for g := 0; g < games; g++ {
+		first, second := a, b
+		if g%2 == 1 {
+			first, second = b, a
+		}
+		var won *MCTS
+		switch playGame(a.ev, first, second, newBoard(), startSide) {
+		case startSide:
+			won = first
+		case other:
+			won = second
+		}
+		switch won {
+		case a:
+			res.AWins++
+		case b:
+			res.BWins++
+		default:
+			res.Draws++
+		}
+	}
+	return res
+}
+
+// playGame plays a game on board where first plays startSide and second the other side,
+// and returns the winner.
+func playGame(ev Evaluator, first, second *MCTS, board [][]int, startSide int) int {
+	side := startSide
+	var last Move
+	lastSide := 0
+	for {
+		player := second
+		if side == startSide {
+			player = first
+		}
+		m, _ := player.Search(board, side, 0, 0, 0)
+		if m == nil {
+			return player.root.winner
+		}
+		gameOver, winner, err := ev.ApplyMove(board, side, m)
+		if err != nil {
+			panic(&ErrEvaluator{Phase: "match", Err: err})
+		}
+		if gameOver {
+			return winner
+		}
+		if first.isPass(m, side) && first.isPass(last, lastSide) {
+			// two consecutive passes end the game as a draw
+			return first.draw
+		}
+		last, lastSide = m, side
+		side = ev.NextPlayer(side)
+	}
+}
@@ -0,0 +1,39 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPlayMatch(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	a, b := New(ev, ev), New(ev, ev)
+	a.SetDefaultIterations(300)
+	b.SetDefaultIterations(5)
+	res := PlayMatch(a, b, func() [][]int { return newBoard(3, 3) }, 1, 6)
+	if res.AWins+res.BWins+res.Draws != 6 {
+		t.Fatalf("expected 6 games, got %+v", res)
+	}
+	if res.BWins >= res.AWins+res.Draws {
+		t.Fatalf("expected the stronger search not to lose most games, got %+v", res)
+	}
+}
+
+func TestPlayMatchSides(t *testing.T) {
+	// player 1 wins every game, so the wins follow the search that plays player 1
+	g := &marginGame{r: rand.New(rand.NewSource(1))}
+	a, b := New(g, g), New(g, g)
+	for side, want := range map[int]MatchResult{1: {AWins: 2, BWins: 1}, 2: {AWins: 1, BWins: 2}} {
+		if res := PlayMatch(a, b, func() [][]int { return newBoard(1, 2) }, side, 3); res != want {
+			t.Fatalf("expected %+v when starting with player %v, got %+v", want, side, res)
+		}
+	}
+
+	rg := &rotatingGame{tttEval: newTTTEval(3, 1)}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a game of three sides to panic")
+		}
+	}()
+	PlayMatch(New(rg, rg), New(rg, rg), func() [][]int { return newBoard(3, 3) }, 1, 1)
+}
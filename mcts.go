@@ -4,114 +4,705 @@ package mcts
 
 import (
 	"math"
+	"math/rand"
+	"runtime"
+	"sync"
 	"time"
 )
 
 // MCTS is the Monte Carlo Tree Search structure
 type MCTS struct {
-	ev Evaluator
-	ex Expander
+	ev       Evaluator
+	ex       Expander
+	ts       TrajectorySink
+	recorder *SelfPlayRecorder
+	book     Book
+
+	verifyBook bool
+
+	clock Clock
+	arena *boardArena
+
+	scoreMapper func(score float64) float64
+	handicap    map[int]float64
+	backupMode  BackupMode
+	moveEqual   func(a, b Move) bool
+	moveFilter  MoveFilter
+	valueSign   func(nodeSide, winner int) float64
+	draw        int
+	drawReward  float64
+	discount    float64
+
+	explorationC      float64
+	rootExploration   float64
+	priorMean         float64
+	priorCount        float64
+	explorationBySide map[int]float64
+	depthExploration  func(depth int) float64
+
+	reuseTree          bool
+	warm               []warmPrior
+	strictDeadline     bool
+	keepSearching      bool
+	avoidLosses        bool
+	defaultIters       int
+	lastMaxDepth       int
+	yieldEvery         int
+	resolveExtra       int
+	preExpandRoot      bool
+	dedupMoves         bool
+	expansionThreshold int64
+	memBudget          int64
+	memUsed            int64
+	compactNodes       bool
+	widenC             float64
+	widenAlpha         float64
+	unpruneInitial     int
+	unpruneThresholds  []int64
+	expandBudget       time.Duration
+
+	distanceTolerance float64
+	minTrustVisits    int64
+	includeUnvisited  bool
+	store             TranspositionStore
+	simultaneous      bool
+	noSolver          bool
+	calibration       float64
+	pause             *pauser
+	crn               bool
+	crnSeed           int64
+	forcedCredit      bool
+	phaseSplit        float64
+	expandTime        time.Duration
+	rolloutTime       time.Duration
+	finalRanker       Ranker
+	initialValue      float64
+	hasInitialValue   bool
+	riskAversion      float64
+	defensive         bool
+	maxPlayoutCost    float64
+	maxRolloutMoves   int
+	warnf             func(format string, args ...interface{})
+	warnedRunaway     bool
+
+	policy        PlayoutPolicy
+	policyBySide  map[int]PlayoutPolicy
+	playoutRand   *rand.Rand
+	selectionRand *rand.Rand
+	tieBreak      TieBreak
+	tieRng        *rand.Rand
+
+	replay       []ReplayEntry
+	timing       bool
+	debugChecks  bool
+	pathObserver func(length int)
+
+	concurrent bool
+	statsMu    *sync.Mutex
+
+	sharing     bool
+	sharedStats map[stateKey]*nodeStats
+
+	root     *treeNode
+	ucbGen   int64
+	rollouts int64
+	// terminals is the number of rollouts of the last search that ended the game.
+	terminals int64
+	// rolloutScore is the total rollout reward of the side to move at the root.
+	rolloutScore float64
+	rolloutWins  map[int]int64
 }
 
 // New returns a new MCTS structure.
 func New(ev Evaluator, ex Expander) *MCTS {
 	return &MCTS{
-		ev: ev,
-		ex: ex,
+		ev:              ev,
+		ex:              ex,
+		moveEqual:       defaultMoveEqual,
+		explorationC:    math.Sqrt2,
+		clock:           realClock{},
+		maxRolloutMoves: defaultMaxRolloutMoves,
+		pause:           newPauser(),
 	}
 }
 
 // Search searches the best Move for a side given a board for a limited duration.
+// duration and maxIters are independent caps and the search stops at whichever is hit first.
+// If duration is less than or equal to 0, the search will only be limited by maxIters.
+// The clock is not read on every iteration, so duration may be overshot by about a millisecond.
 // If maxIters is less than or equal to 0, the iteration count will only be limited by duration.
+// At least one iteration is always run, so if neither cap is set the search runs exactly once,
+// unless a number of default iterations is set with SetDefaultIterations.
+// A search also stops once the outcome of board is proven, see SetKeepSearchingAfterProof.
+// If board is already a finished game, which is detected with IsTerminal if the Evaluator
+// implements TerminalChecker and with an empty Expand result otherwise, no iterations are run
+// and Search returns a nil Move and 0 visits.
+// The final Move is chosen by the first of these rules that applies: a Move that achieves the
+// proven outcome unless SetKeepSearchingAfterProof is set, the Ranker of SetFinalRanker, the
+// Score of the root children if no rollout reached a terminal, see ReachedTerminal, the own action
+// of SetSimultaneousMoves, the value in Minimax backup mode, SetDefensiveSelection at the root,
+// SetRiskAversion, and otherwise the most visited child, whose ties are broken with
+// SetSelectionRand. The same rules choose the best child below the root, for example in the
+// principal variation.
 func (s *MCTS) Search(board [][]int, side int, duration time.Duration, maxDepth, maxIters int) (Move, int64) {
-	t0 := time.Now()
+	return s.SearchWithBudget(board, side, SearchBudget{Duration: duration, MaxDepth: maxDepth, MaxIters: maxIters})
+}
+
+// SearchWithBudget searches like Search with the caps given in b.
+func (s *MCTS) SearchWithBudget(board [][]int, side int, b SearchBudget) (Move, int64) {
+	if s.book != nil {
+		if m, ok := s.book.Lookup(board, side); ok {
+			return s.bookMove(board, side, b, m)
+		}
+	}
+	root := s.search(board, side, b)
+	if root.gameOver {
+		return nil, 0
+	}
+	if len(root.children) == 0 {
+		return s.fallbackMove(root, side), root.visits
+	}
+	return s.bestChild(root).move, root.visits
+}
+
+// SearchWithRootMoves searches like SearchWithBudget, but the root children are created from
+// moves instead of with the Expander, which is only used for deeper nodes. This avoids
+// recomputing root moves that are already known, for example from a policy network, and the
+// Eval of every Move acts as its prior like for expanded Moves. The tree of a previous search
+// is never reused. If moves is empty, the root is expanded with the Expander as usual.
+func (s *MCTS) SearchWithRootMoves(board [][]int, side int, moves []Move, b SearchBudget) (Move, int64) {
+	root := s.searchFrom(board, side, b, moves)
+	if root.gameOver {
+		return nil, 0
+	}
+	if len(root.children) == 0 {
+		return s.fallbackMove(root, side), root.visits
+	}
+	return s.bestChild(root).move, root.visits
+}
+
+// SearchIterations searches like Search, limited only by a number of iterations.
+func (s *MCTS) SearchIterations(board [][]int, side int, iters int) (Move, int64) {
+	return s.Search(board, side, 0, 0, iters)
+}
+
+// SearchFor searches like Search, but the returned Move and its value are chosen from the
+// perspective of optimizeFor, which can differ from side, the player to move.
+// If optimizeFor is side, the Move is the same that Search would return. Otherwise the Move
+// with the highest value for optimizeFor is returned.
+func (s *MCTS) SearchFor(board [][]int, side, optimizeFor int, duration time.Duration, maxDepth, maxIters int) (Move, float64, int64) {
+	root := s.search(board, side, SearchBudget{Duration: duration, MaxDepth: maxDepth, MaxIters: maxIters})
+	if root.gameOver {
+		return nil, 0, 0
+	}
+	if len(root.children) == 0 {
+		return s.fallbackMove(root, side), 0, root.visits
+	}
+	best := s.bestChild(root)
+	if optimizeFor != side {
+		for _, ch := range root.children {
+			if ch.valueFor(optimizeFor) > best.valueFor(optimizeFor) {
+				best = ch
+			}
+		}
+	}
+	return best.move, best.valueFor(optimizeFor), root.visits
+}
+
+func (s *MCTS) search(board [][]int, side int, b SearchBudget) *treeNode {
+	return s.searchFrom(board, side, b, nil)
+}
+
+// searchFrom searches like search, with the root children created from rootMoves if it is not
+// empty instead of with the Expander.
+func (s *MCTS) searchFrom(board [][]int, side int, b SearchBudget, rootMoves []Move) *treeNode {
+	s.pause.enterRun()
+	defer s.pause.exitRun()
+	clock := newBudgetClock(s.clock)
+	s.ucbGen++
+	if b.Duration <= 0 && b.MaxIters <= 0 {
+		b.MaxIters = s.defaultIters
+	}
+	var root *treeNode
+	if len(rootMoves) == 0 {
+		root = s.reusableRoot(board, side)
+	}
+	if root == nil {
+		root = s.newRoot(board, side)
+	}
+	s.root = root
+	s.memUsed = treeFootprint(root)
+	if !root.gameOver && len(root.children) == 0 {
+		for _, m := range rootMoves {
+			s.addChild(root, &treeNode{}, m, root.board)
+		}
+	}
+	s.lastMaxDepth = b.MaxDepth
+	s.run(root, b, clock)
+	s.warm = nil
+	if s.resolveExtra > 0 {
+		s.resolveDisagreement(root, b.MaxDepth)
+	}
+	if s.store != nil {
+		s.storeTranspositions(root)
+	}
+	if s.recorder != nil {
+		s.recorder.record(root, root.board, side)
+	}
+	return root
+}
+
+// newRoot returns the root of a new tree for side to move on board.
+func (s *MCTS) newRoot(board [][]int, side int) *treeNode {
+	if s.arena != nil {
+		s.arena.reset()
+	}
+	// the root keeps its own copy so that the caller can modify board after the search
 	root := &treeNode{
 		children: make([]*treeNode, 0),
-		board:    board,
+		board:    s.boardCopy(board),
 		depth:    0,
 		side:     s.ev.PrevPlayer(side),
+		toMove:   side,
+	}
+	root.gameOver, root.winner = s.terminalRoot(root.board, side)
+	if r, ok := s.ev.(GameOverReasoner); ok && root.gameOver {
+		root.reason = r.GameOverReason(root.board, root.winner)
+	}
+	s.setRootHash(root)
+	if s.sharing {
+		s.sharedStats = make(map[stateKey]*nodeStats)
+	}
+	return root
+}
+
+// run runs the iterations of a search from n within b, whose time is measured by clock. The
+// caller registers the whole search with the pauser, which run waits for between iterations.
+func (s *MCTS) run(n *treeNode, b SearchBudget, clock *budgetClock) {
+	s.rollouts = 0
+	s.terminals = 0
+	s.rolloutScore = 0
+	s.rolloutWins = make(map[int]int64)
+	s.expandTime, s.rolloutTime = 0, 0
+	if s.preExpandRoot && n.parent == nil && !n.gameOver {
+		s.preExpand(n, b.MaxDepth)
 	}
-	var node *treeNode
 	iter := 0
-	// run this loop at least once
-	for iter == 0 || time.Since(t0) < duration {
-		if maxIters > 0 && iter >= maxIters {
+	// run this loop at least once unless the deadline is strict
+	for !n.gameOver && ((iter == 0 && !s.strictDeadline) || !b.done(iter, clock.since(iter, b.Duration))) {
+		s.pause.wait()
+		if n.proven && !s.keepSearching && iter >= b.MinIters {
 			break
 		}
 		iter++
-		node = promisingNode(root)
-		node.expand(s.ev, s.ex, maxDepth)
-		node = firstChildOrItself(node)
-		s.randomPlayOut(node)
-		backpropagate(node)
+		s.iterate(n, b.MaxDepth)
+		if s.yieldEvery > 0 && iter%s.yieldEvery == 0 {
+			yield()
+		}
 	}
+}
 
-	return bestChild(root).move, root.visits
+// iterate runs a single selection, expansion, rollout and backpropagation step from root.
+func (s *MCTS) iterate(root *treeNode, maxDepth int) {
+	var t0 time.Time
+	if s.timing {
+		t0 = s.clock.Now()
+	}
+	node := s.promisingNode(root)
+	if s.pathObserver != nil {
+		s.pathObserver(node.depth - root.depth)
+	}
+	expanded := len(node.children)
+	var t1 time.Time
+	if s.phaseSplit > 0 {
+		t1 = s.clock.Now()
+	}
+	s.expand(node, maxDepth)
+	if s.arena != nil {
+		// only the boards of new nodes outlive the iteration
+		defer s.arena.release(s.arena.mark())
+	}
+	if s.phaseSplit > 0 {
+		t2 := s.clock.Now()
+		s.addPhaseTime(&s.expandTime, t2.Sub(t1))
+		t1 = t2
+	}
+	leaf := firstChildOrItself(node)
+	o := s.playout(leaf)
+	if s.phaseSplit > 0 {
+		s.addPhaseTime(&s.rolloutTime, s.clock.Now().Sub(t1))
+	}
+	s.recordTrajectory(leaf, o.moves, o.winner)
+	if s.replay != nil {
+		s.replay = append(s.replay, ReplayEntry{
+			Path:     childPath(node),
+			Expanded: childMoves(node.children[expanded:]),
+			Rollout:  o.moves,
+			Winner:   o.winner,
+		})
+	}
+	s.backpropagate(leaf, o)
+	if s.timing {
+		elapsed := s.clock.Now().Sub(t0)
+		for n := leaf; n != nil; n = n.parent {
+			n.elapsed += elapsed
+		}
+	}
 }
 
-func (s *MCTS) randomPlayOut(n *treeNode) {
-	if n.gameOver {
-		return
+// playout returns the outcome of a rollout from leaf.
+func (s *MCTS) playout(leaf *treeNode) outcome {
+	if leaf.gameOver {
+		// terminal leaves have a fixed outcome, no rollout is needed
+		return outcome{winner: leaf.winner, board: s.boardOf(leaf), terminal: true}
+	}
+	return s.randomPlayOut(leaf)
+}
+
+// preExpand expands root and runs a rollout from every child that has none, so that every root
+// Move is in the tree with a visit before the first selection.
+func (s *MCTS) preExpand(root *treeNode, maxDepth int) {
+	if len(root.children) == 0 {
+		s.expand(root, maxDepth)
+	}
+	for _, ch := range root.children {
+		if ch.iters == 0 {
+			o := s.playout(ch)
+			s.recordTrajectory(ch, o.moves, o.winner)
+			s.backpropagate(ch, o)
+		}
 	}
-	currentTurn := s.ev.NextPlayer(n.side)
+}
+
+// SetPreExpandRoot sets whether a search expands the root and runs a rollout from every root
+// child before the first iteration, which guarantees a visit for every root Move regardless of
+// the budget. These rollouts do not count toward the iterations of the budget.
+// Progressive widening still limits the root children that are added. It is disabled by default.
+func (s *MCTS) SetPreExpandRoot(preExpand bool) {
+	s.preExpandRoot = preExpand
+}
+
+// terminalRoot reports whether board, with side to move, is already a finished game.
+func (s *MCTS) terminalRoot(board [][]int, side int) (bool, int) {
+	if tc, ok := s.ev.(TerminalChecker); ok {
+		return tc.IsTerminal(board)
+	}
+	return len(s.expandAt(board, side, 0)) == 0, s.draw
+}
 
-	board := copyBoard(n.board)
-	for !n.gameOver {
-		m := s.ev.RandomMove(board, currentTurn)
+// SetDefaultIterations sets the number of iterations a search runs when neither duration nor
+// maxIters is set, which would otherwise run a single iteration and return a nearly random Move.
+// A value less than or equal to 0, which is the default, keeps the single iteration.
+func (s *MCTS) SetDefaultIterations(iters int) {
+	s.defaultIters = iters
+}
+
+// SetKeepSearchingAfterProof sets whether a search continues after the outcome of the root is
+// proven, which gathers richer statistics for example for generating training data.
+// By default a search stops as soon as the root is proven, since more iterations can not change
+// its outcome, unless it has not run the minimum number of iterations of its budget, and the
+// final Move is then one that achieves the proven outcome.
+// When the search continues, the final Move is chosen from the statistics as usual.
+func (s *MCTS) SetKeepSearchingAfterProof(keep bool) {
+	s.keepSearching = keep
+}
+
+// SetExpansionThreshold sets the number of visits a leaf needs before it is expanded. Until then,
+// iterations that select the leaf run rollouts from it, which keeps rarely visited lines out of
+// the tree. The root is always expanded. The default of 0 expands a leaf when it is first selected.
+func (s *MCTS) SetExpansionThreshold(visits int64) {
+	s.expansionThreshold = visits
+}
+
+// yield is called by a search that cooperates with other goroutines.
+var yield = runtime.Gosched
+
+// SetCooperativeYield sets the number of iterations after which a search yields the processor
+// with runtime.Gosched, so that a long search does not starve other goroutines.
+// A value less than or equal to 0, which is the default, never yields.
+func (s *MCTS) SetCooperativeYield(everyK int) {
+	s.yieldEvery = everyK
+}
+
+// SetStrictDeadline sets whether Search may skip the guaranteed first iteration.
+// With a strict deadline, a Search whose caps are already hit when it starts runs no iterations
+// and returns the best Move of a reused tree, or a RandomMove if there is no tree to use.
+func (s *MCTS) SetStrictDeadline(strict bool) {
+	s.strictDeadline = strict
+}
+
+// RolloutBalance returns the fraction of rollouts of the last search won by each side,
+// with draws under the draw sentinel. A heavily skewed balance on a fair game usually points to
+// a broken Evaluator.
+func (s *MCTS) RolloutBalance() map[int]float64 {
+	res := make(map[int]float64)
+	for w, c := range s.rolloutWins {
+		res[w] = float64(c) / float64(s.rollouts)
+	}
+	return res
+}
+
+// fallbackMove returns the Move for side when the search could not produce a child of root.
+func (s *MCTS) fallbackMove(root *treeNode, side int) Move {
+	return s.ev.RandomMove(s.nodeBoard(root), side)
+}
+
+// outcome is the result of a rollout.
+// board is the final board, which is used for scoring when a score mapper is set.
+// moves are the rollout moves, which are only collected when they are recorded, and plies
+// is the number of rollout moves.
+type outcome struct {
+	winner int
+	board  [][]int
+	moves  []Move
+	plies  int
+	// terminal is set if the game ended, rather than the rollout being cut short.
+	terminal bool
+}
+
+// randomPlayOut plays random moves from n, which must not be terminal, until the game is over
+// and returns the outcome.
+func (s *MCTS) randomPlayOut(n *treeNode) outcome {
+	var moves []Move
+	currentTurn := s.toMove(n)
+
+	board := s.nodeBoard(n)
+	if s.crn {
+		s.seedPlayout(n)
+	}
+	winner := s.draw
+	passed := s.isPass(n.move, n.side)
+	plies := 0
+	cost := 0.0
+	terminal := true
+	resigner, _ := s.ev.(Resigner)
+	for {
+		if resigner != nil {
+			if resign, w := resigner.ShouldResign(board, currentTurn); resign {
+				winner = w
+				break
+			}
+		}
+		m := s.playoutMove(board, currentTurn)
 		if m == nil {
 			break
 		}
-		gameOver, winner, err := s.ev.ApplyMove(board, currentTurn, m)
+		if s.debugChecks {
+			s.checkRolloutMove(board, currentTurn, n.depth+plies, m)
+		}
+		gameOver, w, c, err := s.applyCost(board, currentTurn, m)
 		if err != nil {
-			panic(err)
+			panic(&ErrEvaluator{Phase: "rollout", Err: err})
+		}
+		plies++
+		cost += c
+		if s.ts != nil || s.replay != nil {
+			moves = append(moves, m)
 		}
 		if gameOver {
-			n.winner = winner
+			winner = w
+			break
+		}
+		if s.maxPlayoutCost > 0 && cost >= s.maxPlayoutCost {
+			// the rollout ran out of resources, which is scored like a draw
+			terminal = false
+			break
+		}
+		if s.maxRolloutMoves > 0 && plies >= s.maxRolloutMoves {
+			s.warnRunaway()
+			terminal = false
+			break
+		}
+		pass := s.isPass(m, currentTurn)
+		if pass && passed {
+			// two consecutive passes end the game as a draw
 			break
 		}
+		passed = pass
 		currentTurn = s.ev.NextPlayer(currentTurn)
 	}
+	return outcome{winner: winner, board: board, moves: moves, plies: plies, terminal: terminal}
+}
+
+// recordTrajectory passes the moves leading to n followed by the rollout moves to the
+// trajectory sink, if there is one.
+func (s *MCTS) recordTrajectory(n *treeNode, rollout []Move, winner int) {
+	if s.ts == nil {
+		return
+	}
+	s.ts.Record(append(pathMoves(n), rollout...), winner)
+}
+
+// pathMoves returns the moves leading from the root to n.
+func pathMoves(n *treeNode) []Move {
+	res := make([]Move, n.depth)
+	for ; n.parent != nil; n = n.parent {
+		res[n.depth-1] = n.move
+	}
+	return res
 }
 
-func (n *treeNode) expand(ev Evaluator, ex Expander, maxDepth int) {
+func (s *MCTS) expand(n *treeNode, maxDepth int) {
 	if n.gameOver {
 		return
 	}
 	if maxDepth > 0 && n.depth >= maxDepth {
 		return
 	}
-	nextPlayer := ev.NextPlayer(n.side)
-	moves := ex.Expand(n.board, nextPlayer)
-	for _, m := range moves {
-		board := copyBoard(n.board)
-		child := &treeNode{
-			children: make([]*treeNode, 0),
-			board:    board,
-			depth:    n.depth + 1,
-			move:     m,
-			parent:   n,
-			side:     nextPlayer,
-		}
-		n.children = append(n.children, child)
-		gameOver, winner, err := ev.ApplyMove(board, nextPlayer, m)
+	if s.memBudget > 0 && s.memUsed >= s.memBudget {
+		return
+	}
+	if n.parent != nil && n.visits < s.expansionThreshold {
+		// the leaf keeps running rollouts until it has enough visits
+		return
+	}
+	if n.parent != nil && s.overExpandShare() {
+		return
+	}
+	nextPlayer := s.toMove(n)
+	parentBoard := s.expansionBoard(n)
+	if le, ok := s.ex.(LazyExpander); ok && s.widening() {
+		board := n.board
+		if board == nil {
+			// the iterator outlives the rebuilt board of a compact node
+			board = cloneBoard(s.ev, parentBoard)
+		}
+		n.lazy = le.ExpandLazy(board, nextPlayer)
+		s.widen(n)
+		return
+	}
+	moves := s.expandAt(parentBoard, nextPlayer, n.depth)
+	if s.moveFilter != nil {
+		all := len(moves)
+		moves = s.filterMoves(moves, n.depth)
+		n.filtered = len(moves) < all
+	}
+	if s.dedupMoves {
+		moves = s.distinctMoves(moves)
+	}
+	if s.widening() {
+		n.pending = rankMoves(moves)
+		s.widen(n)
+		return
+	}
+	if s.expandBudget > 0 {
+		n.pending = moves
+		s.widen(n)
+		return
+	}
+	var slab []treeNode
+	if h, ok := s.ex.(MoveCountHinter); ok {
+		capacity := h.MaxMoves()
+		if capacity < len(moves) {
+			capacity = len(moves)
+		}
+		n.children = make([]*treeNode, 0, capacity)
+		slab = make([]treeNode, len(moves))
+	}
+	for i, m := range moves {
+		var child *treeNode
+		if slab != nil {
+			child = &slab[i]
+		} else {
+			child = &treeNode{}
+		}
+		s.addChild(n, child, m, parentBoard)
+	}
+}
+
+// expansionBoard returns the board of n that children are created from.
+func (s *MCTS) expansionBoard(n *treeNode) [][]int {
+	if s.compactNodes {
+		// compact children are applied to and reverted from a copy of the board
+		return s.nodeBoard(n)
+	}
+	return n.board
+}
+
+// addChild initializes child as the node of m played from n, whose board is parentBoard,
+// and appends it to the children of n.
+func (s *MCTS) addChild(n, child *treeNode, m Move, parentBoard [][]int) {
+	ev := s.ev
+	nextPlayer := s.toMove(n)
+	*child = treeNode{
+		children: make([]*treeNode, 0),
+		depth:    n.depth + 1,
+		move:     m,
+		parent:   n,
+		side:     nextPlayer,
+	}
+	n.children = append(n.children, child)
+	var gameOver bool
+	var winner int
+	bm, precomputed := m.(BoardMove)
+	tc, checkable := ev.(TerminalChecker)
+	dm, delta := m.(DeltaMove)
+	delta = delta && s.compactNodes
+	if precomputed && checkable {
+		child.board = bm.ResultingBoard()
+		gameOver, winner = tc.IsTerminal(child.board)
+	} else if delta {
+		var err error
+		gameOver, winner, err = ev.ApplyMove(parentBoard, nextPlayer, m)
 		if err != nil {
-			panic(err)
+			panic(&ErrEvaluator{Phase: "expand", Err: err})
 		}
-		if gameOver {
-			child.gameOver = true
-			child.winner = winner
+		dm.Revert(parentBoard)
+	} else {
+		child.board = s.boardCopy(parentBoard)
+		var err error
+		gameOver, winner, err = ev.ApplyMove(child.board, nextPlayer, m)
+		if err != nil {
+			panic(&ErrEvaluator{Phase: "expand", Err: err})
+		}
+	}
+	if delta {
+		child.board = nil
+	}
+	s.memUsed += nodeFootprint(child)
+	if !gameOver && s.isPass(m, nextPlayer) && s.isPass(n.move, n.side) {
+		// two consecutive passes end the game as a draw
+		gameOver, winner = true, s.draw
+		child.reason = ReasonDoublePass
+	}
+	if gameOver {
+		if r, ok := ev.(GameOverReasoner); ok && child.reason == ReasonUnknown {
+			child.reason = r.GameOverReason(s.boardOf(child), winner)
 		}
+		child.gameOver = true
+		child.winner = winner
+		child.proven = true
+		child.provenWinner = winner
+		child.mmValue = child.terminalValue(s.draw, s.drawReward)
+	}
 
-		side := child.side
-		for child != nil {
-			child.visits++
-			if child.side == side {
-				child.winScore += m.Eval()
-			} else {
-				child.winScore -= m.Eval()
-			}
-			child = child.parent
+	if h, ok := ev.(Hasher); ok {
+		child.hash = h.HashAfter(n.hash, m, nextPlayer)
+	}
+	if s.sharing {
+		s.shareStats(child)
+	}
+
+	side := child.side
+	for child != nil {
+		child.visits++
+		eval := m.Eval()
+		if child.side != side {
+			eval = -eval
+		}
+		child.winScore += eval
+		child.sqScore += eval * eval
+		if child.shared != nil {
+			child.shared.visits++
+			child.shared.winScore += eval
 		}
+		child = child.parent
+	}
+	if n.parent == nil && s.warm != nil {
+		s.warmStart(n.children[len(n.children)-1])
+	}
+	if s.store != nil {
+		s.loadTransposition(n.children[len(n.children)-1])
 	}
 }
 
@@ -122,22 +713,78 @@ func firstChildOrItself(n *treeNode) *treeNode {
 	return n.children[0]
 }
 
+// bestChild returns the child of n that is chosen as the final move by the rules listed on Search.
+func (s *MCTS) bestChild(n *treeNode) *treeNode {
+	if !s.keepSearching {
+		// the search stops before the statistics favor the proven move
+		if ch := provenChild(n, s.draw); ch != nil {
+			return ch
+		}
+	}
+	var res *treeNode
+	switch {
+	case s.finalRanker != nil:
+		res = s.rankedChild(n)
+	case s.scoreFallback(n):
+		res = s.scoredChild(n)
+	case s.simultaneous:
+		res = s.simultaneousChild(n)
+	case s.backupMode == Minimax:
+		res = bestMinimaxChild(n, s.minTrustVisits)
+	case s.defensive && n.parent == nil:
+		res = s.defensiveChild(n)
+	case s.riskAversion > 0:
+		res = s.riskAdjustedChild(n)
+	case s.selectionRand != nil:
+		res = randomBestChild(n, s.selectionRand)
+	default:
+		res = bestChild(n)
+	}
+	if s.distanceTolerance > 0 {
+		res = s.distanceTiebreak(n, res)
+	}
+	return res
+}
+
 func bestChild(n *treeNode) *treeNode {
 	if len(n.children) == 0 {
 		panic("could not find any children")
 	}
 	res := n.children[0]
-	maxVisits := res.visits
 	for i := 1; i < len(n.children); i++ {
 		ch := n.children[i]
-		if ch.visits > maxVisits {
+		if ch.visits > res.visits || (ch.visits == res.visits && ch.value() > res.value()) {
 			res = ch
-			maxVisits = ch.visits
 		}
 	}
 	return res
 }
 
+// randomBestChild returns the child of n that bestChild would choose, breaking ties
+// between children with equal visits and values uniformly at random.
+func randomBestChild(n *treeNode, r *rand.Rand) *treeNode {
+	res := bestChild(n)
+	ties := 0
+	for _, ch := range n.children {
+		if ch.visits == res.visits && ch.value() == res.value() {
+			ties++
+			if r.Intn(ties) == 0 {
+				res = ch
+			}
+		}
+	}
+	return res
+}
+
+// cloneBoard copies board with the Evaluator's CloneState if it implements StateCloner,
+// and with copyBoard otherwise.
+func cloneBoard(ev Evaluator, board [][]int) [][]int {
+	if c, ok := ev.(StateCloner); ok {
+		return c.CloneState(board)
+	}
+	return copyBoard(board)
+}
+
 func copyBoard(board [][]int) [][]int {
 	res := make([][]int, len(board))
 	for i, row := range board {
@@ -150,50 +797,217 @@ func copyBoard(board [][]int) [][]int {
 }
 
 // treeNode is the search tree node.
-// side is 1 for player 1 and 2 for player 2. For board games with more players,
-// side can be 3 or more.
-// winner is 0 for a draw, 1 for player 1 and 2 for player 2 and so on.
+// side is the side that played move, which is 1 for player 1 and 2 for player 2. For board games
+// with more players, side can be 3 or more. The statistics of a node are from the perspective of side.
+// toMove is the side to move at a root, which can not be derived from side with NextPlayer like for
+// other nodes. The side of a root only sets the perspective of its statistics, and is PrevPlayer of
+// toMove for a new root, so that it matches the side of a reused root and of the nodes of the same
+// position in the transposition store, and nothing else depends on PrevPlayer being the inverse of
+// NextPlayer.
+// winner is the draw sentinel for a draw, 1 for player 1 and 2 for player 2 and so on.
 type treeNode struct {
 	parent   *treeNode
 	children []*treeNode
 	side     int
+	toMove   int
 	move     Move
 	winner   int
 	winScore float64
+	sqScore  float64
 	visits   int64
 	gameOver bool
+	reason   GameOverReason
 	level    int
 	board    [][]int
 	depth    int
+	mmValue  float64
+	// iters is the number of iterations whose rollout started in the subtree of the node,
+	// and elapsed the time spent in them if timing is enabled.
+	iters   int64
+	elapsed time.Duration
+	// plySum is the sum of the plies from the node to the end of the game in those iterations.
+	plySum int64
+	// shared holds the statistics shared with equivalent nodes if sharing is enabled.
+	shared *nodeStats
+	// hash is the hash of the board if the Evaluator implements Hasher.
+	hash uint64
+	// pending holds the ranked Moves that progressive widening has not added yet.
+	pending []Move
+	// lazy yields the Moves after pending of a LazyExpander, until it is exhausted and set to nil.
+	lazy MoveIterator
+	// proven is set when the outcome of the node is known regardless of the remaining
+	// moves, in which case provenWinner is the winner.
+	proven       bool
+	provenWinner int
+	// filtered is set when the move filter dropped Moves of the node, which is then not proven
+	// by the outcomes of its children alone.
+	filtered bool
+	// loaded is set when the statistics of the node were loaded from the transposition store.
+	loaded bool
+	// ucb caches the terms of the UCB value of the node.
+	ucb ucbCache
+	// unweighted is the part of the visits that confidence weighted rollouts did not add.
+	unweighted float64
+}
+
+// ucbCache holds the UCB terms of a node, which are valid for the search generation gen as long
+// as the node and its shared statistics have the given visits.
+type ucbCache struct {
+	gen, visits, shared int64
+	mean, inv           float64
+}
+
+// toMove returns the side to move at n.
+func (s *MCTS) toMove(n *treeNode) int {
+	if n.parent == nil {
+		return n.toMove
+	}
+	return s.ev.NextPlayer(n.side)
+}
+
+// value returns the mean evaluation of n from the perspective of n.side.
+func (n *treeNode) value() float64 {
+	if n.visits == 0 {
+		return 0
+	}
+	return n.winScore / n.weight()
+}
+
+// weight returns the effective visits of n, which only differ from its visits if rollouts are
+// confidence weighted. It is at least 1 for a visited node.
+func (n *treeNode) weight() float64 {
+	return math.Max(1, float64(n.visits)-n.unweighted)
 }
 
-func promisingNode(n *treeNode) *treeNode {
+// valueFor returns the mean evaluation of n from the perspective of side.
+func (n *treeNode) valueFor(side int) float64 {
+	if n.side == side {
+		return n.value()
+	}
+	return -n.value()
+}
+
+func (s *MCTS) promisingNode(n *treeNode) *treeNode {
 	if n.gameOver {
 		return n
 	}
 	res := n
 	for len(res.children) > 0 {
-		res = highestUCBChild(res)
+		if forced(res) {
+			// a forced move is selected without computing UCB values
+			res = res.children[0]
+			continue
+		}
+		s.widen(res)
+		res = s.highestUCBChild(res)
 	}
 	return res
 }
 
-func highestUCBChild(n *treeNode) *treeNode {
-	parentVisits := float64(n.visits)
+// forced reports whether n has a single Move, which is its only child.
+func forced(n *treeNode) bool {
+	return len(n.children) == 1 && !n.hasPending()
+}
+
+// hasPending reports whether n may have Moves that are not children yet.
+func (n *treeNode) hasPending() bool {
+	return len(n.pending) > 0 || n.lazy != nil
+}
+
+// SetForcedMoveCredit sets whether the exploitation term of the UCB value of a node whose
+// only reply is forced is the value of that reply, from the perspective of the node, instead of
+// its own mean value. The mean value of the node also counts the visits it had before its reply
+// was expanded, while the reply leads to the only continuation of the game. It is disabled by
+// default.
+func (s *MCTS) SetForcedMoveCredit(credit bool) {
+	s.forcedCredit = credit
+}
+
+// exploitation returns the exploitation term of the UCB value of n.
+func (s *MCTS) exploitation(n *treeNode) float64 {
+	if s.forcedCredit && forced(n) && n.children[0].visits > 0 {
+		if ch := n.children[0]; ch.side != n.side {
+			return -s.exploitation(ch)
+		}
+		return s.exploitation(n.children[0])
+	}
+	if s.hasInitialValue && !n.gameOver && unexplored(n) {
+		return s.initialValue
+	}
+	if s.backupMode == Minimax {
+		return n.minimaxValue()
+	}
+	winScore, visits := n.winScore, float64(n.visits)-n.unweighted
+	if n.shared != nil {
+		winScore, visits = n.shared.winScore, float64(n.shared.visits)
+	}
+	return (winScore + s.priorMean*s.priorCount) / (visits + s.priorCount)
+}
+
+// SetValueSmoothing sets a prior that smooths the exploitation term of the UCB value, which
+// becomes (winScore + priorMean*priorCount) / (visits + priorCount). This stabilizes the
+// selection among nodes with few visits, whose mean values are noisy. The smoothing does not
+// apply in Minimax backup mode. The default priorCount of 0 disables smoothing.
+func (s *MCTS) SetValueSmoothing(priorMean, priorCount float64) {
+	s.priorMean, s.priorCount = priorMean, priorCount
+}
+
+// SetInitialValue sets the exploitation term of the UCB value of the nodes without statistics
+// besides their expansion visit, which are the new children of an expansion except for the one
+// that the rollout of the expansion starts from, unless a warm start or a transposition store
+// gave them visits. An optimistic value above the values of explored Moves makes selection try
+// every new Move before revisiting one, while a pessimistic value makes it stay with the explored
+// Moves. Unlike priors, the value is the same for every Move. Without an initial value, which is
+// the default and is restored with NaN, a new node starts with the evaluation of its Move.
+func (s *MCTS) SetInitialValue(v float64) {
+	s.initialValue, s.hasInitialValue = v, !math.IsNaN(v)
+}
+
+// unexplored reports whether n has no statistics besides the visit of its expansion.
+func unexplored(n *treeNode) bool {
+	if n.shared != nil {
+		return n.shared.visits <= 1
+	}
+	return n.visits <= 1
+}
+
+// selectionVisits returns the visits of n used for the exploration term of its UCB value.
+func (s *MCTS) selectionVisits(n *treeNode) float64 {
+	if n.shared != nil {
+		return float64(n.shared.visits)
+	}
+	return float64(n.visits) - n.unweighted
+}
+
+func (s *MCTS) highestUCBChild(n *treeNode) *treeNode {
+	if s.simultaneous {
+		if ch := s.simultaneousUCBChild(n); ch != nil {
+			return ch
+		}
+	}
+	if s.avoidLosses {
+		if ch := s.highestUCBUnlostChild(n); ch != nil {
+			return ch
+		}
+	}
+	if s.tieBreak != FirstTie {
+		return s.tieBrokenUCBChild(n)
+	}
 	res := n.children[0]
 	if res.visits == 0 {
 		return res
 	}
-	visits := float64(res.visits)
-	maxVal := (res.winScore / visits) + math.Sqrt2*math.Sqrt(math.Log(parentVisits)/visits)
+	// the parent term is shared by all children, so only the child terms are cached
+	c := s.explorationTerm(n)
+	mean, inv := s.ucbTerms(res)
+	maxVal := mean + c*inv
 	for i := 1; i < len(n.children); i++ {
 		node := n.children[i]
 		if node.visits == 0 {
 			return node
 		}
-		visits = float64(node.visits)
-		val := (node.winScore / visits) + math.Sqrt2*math.Sqrt(math.Log(parentVisits)/visits)
-		if val > maxVal {
+		mean, inv = s.ucbTerms(node)
+		if val := mean + c*inv; val > maxVal {
 			maxVal = val
 			res = node
 		}
@@ -201,17 +1015,192 @@ func highestUCBChild(n *treeNode) *treeNode {
 	return res
 }
 
-func backpropagate(n *treeNode) {
-	winner := n.winner
+// explorationTerm returns the factor of the exploration term of the UCB values of the children
+// of n. Visit counts are clamped to at least 1, so that inconsistent or overflowed counts do
+// not turn UCB values into NaN. The float64 conversion of counts beyond 2^53 loses precision,
+// which does not matter for the logarithm.
+func (s *MCTS) explorationTerm(n *treeNode) float64 {
+	return s.exploration(n) * math.Sqrt(math.Log(math.Max(1, float64(n.visits)-n.unweighted)))
+}
+
+// ucbTerms returns the exploitation term of the UCB value of n and the inverse square root of
+// its selection visits. The terms are cached until the statistics of n change, which always
+// changes its visits, or until the next search, which may change the settings they depend on.
+func (s *MCTS) ucbTerms(n *treeNode) (float64, float64) {
+	var shared int64
+	if n.shared != nil {
+		shared = n.shared.visits
+	}
+	c := &n.ucb
+	if c.gen != s.ucbGen || c.visits != n.visits || c.shared != shared {
+		*c = ucbCache{
+			gen:    s.ucbGen,
+			visits: n.visits,
+			shared: shared,
+			mean:   s.exploitation(n),
+			inv:    1 / math.Sqrt(math.Max(1, s.selectionVisits(n))),
+		}
+	}
+	return c.mean, c.inv
+}
+
+// exploration returns the exploration constant used when selecting among the children of n.
+func (s *MCTS) exploration(n *treeNode) float64 {
+	if n.parent == nil && s.rootExploration > 0 {
+		return s.rootExploration
+	}
+	c := s.explorationC
+	if bySide, ok := s.explorationBySide[n.children[0].side]; ok {
+		c = bySide
+	}
+	if s.depthExploration != nil {
+		c *= s.depthExploration(n.depth + 1)
+	}
+	return c
+}
+
+// SetExploration sets the exploration constant of the UCB value. The default is sqrt(2).
+func (s *MCTS) SetExploration(c float64) {
+	s.explorationC = c
+}
+
+// SetRootExploration sets the exploration constant used when selecting among the root children,
+// which is often higher than elsewhere to make sure that every root Move is explored. It replaces
+// the constants set with SetExploration, SetExplorationBySide and SetDepthExploration at the root.
+// A constant less than or equal to 0, which is the default, uses those constants at the root too.
+func (s *MCTS) SetRootExploration(c float64) {
+	s.rootExploration = c
+}
+
+// SetExplorationBySide sets exploration constants for the sides in bySide, which are used when
+// selecting among nodes whose Move is played by that side, so that for example the searched side
+// explores more than an opponent that is assumed to play well. Sides that are not in bySide use
+// the constant set with SetExploration.
+func (s *MCTS) SetExplorationBySide(bySide map[int]float64) {
+	s.explorationBySide = bySide
+}
+
+// SetDepthExploration sets a function that returns a multiplier for the exploration term of
+// the UCB value of nodes at depth, where the root children are at depth 1. It can be used to
+// reduce exploration deeper in the tree, where statistics are less reliable.
+// A nil function, which is the default, uses a multiplier of 1 at every depth.
+func (s *MCTS) SetDepthExploration(f func(depth int) float64) {
+	s.depthExploration = f
+}
+
+// SetValueSign sets the function that returns the reward of a rollout won by winner for a node
+// whose Move was played by nodeSide. This allows games where sides do not map one to one to
+// players, for example when a side value encodes a player taking a second action in a row.
+// The default, which a nil function restores, returns the draw reward for a draw, 1.0 if
+// winner is nodeSide and -1.0 otherwise.
+func (s *MCTS) SetValueSign(f func(nodeSide, winner int) float64) {
+	s.valueSign = f
+}
+
+// SetDrawSentinel sets the winner that stands for a draw, which is 0 by default. Engines that
+// number their players from 0 can use for example -1, so that wins of player 0 are not taken
+// for draws. The sentinel applies both to the winners reported by the Evaluator and to the
+// winners reported by the search, such as the draws of RolloutBalance.
+func (s *MCTS) SetDrawSentinel(w int) {
+	s.draw = w
+}
+
+// SetDrawReward sets the reward of a draw for every side, which is 0 by default. A negative
+// reward makes the search avoid draws, like a contempt factor in chess engines.
+func (s *MCTS) SetDrawReward(r float64) {
+	s.drawReward = r
+}
+
+// SetDiscount sets a factor between 0 and 1 that rewards are multiplied by for every ply between
+// a node and the end of its rollout, so that quicker wins and slower losses are preferred.
+// Plies are counted as moves even if the Evaluator implements CostReporter.
+// A factor of 0, which is the default, or 1 disables discounting.
+func (s *MCTS) SetDiscount(gamma float64) {
+	s.discount = gamma
+}
+
+// discounted returns the reward r of a game that ended plies after a node.
+func (s *MCTS) discounted(r float64, plies int) float64 {
+	if s.discount <= 0 || s.discount >= 1 {
+		return r
+	}
+	return r * math.Pow(s.discount, float64(plies))
+}
+
+// sign returns the reward of a rollout won by winner for a node whose Move was played by nodeSide.
+func (s *MCTS) sign(nodeSide, winner int) float64 {
+	if s.valueSign != nil {
+		return s.valueSign(nodeSide, winner)
+	}
+	switch winner {
+	case s.draw:
+		return s.drawReward
+	case nodeSide:
+		return 1.0
+	default:
+		return -1.0
+	}
+}
+
+func (s *MCTS) backpropagate(n *treeNode, o outcome) {
+	if s.concurrent {
+		s.backpropagateAtomic(n, o)
+		return
+	}
+	s.rollouts++
+	if o.terminal {
+		s.terminals++
+	}
+	s.rolloutWins[o.winner]++
+	var rewards map[int]float64
+	_, scored := s.ev.(Scorer)
+	if scored && s.scoreMapper != nil {
+		// scores are computed once per side
+		rewards = make(map[int]float64)
+	}
+	reward := func(side int) float64 {
+		if rewards == nil {
+			return s.sign(side, o.winner)
+		}
+		r, ok := rewards[side]
+		if !ok {
+			r = s.reward(o, side)
+			rewards[side] = r
+		}
+		return r
+	}
+	end := n.depth + o.plies
+	w := s.confidence(o)
 	for n != nil {
 		n.visits++
-		if winner != 0 {
-			if winner == n.side {
-				n.winScore += 1.0
-			} else {
-				n.winScore -= 1.0
-			}
+		n.iters++
+		n.plySum += int64(end - n.depth)
+		r := s.discounted(reward(n.side), end-n.depth)
+		n.winScore += w * r
+		n.sqScore += w * r * r
+		n.unweighted += 1 - w
+		if n.shared != nil {
+			n.shared.visits++
+			n.shared.winScore += r
+		}
+		if n.parent == nil {
+			s.rolloutScore += s.discounted(reward(n.toMove), end)
+		}
+		if s.backupMode == Minimax {
+			n.updateMinimax()
+		}
+		if s.solving() {
+			n.updateProof(s.draw)
 		}
 		n = n.parent
 	}
 }
+
+// reward returns the reward of o for side, which is its mapped score if the Evaluator
+// implements Scorer and a score mapper is set, and its value sign otherwise.
+func (s *MCTS) reward(o outcome, side int) float64 {
+	if sc, ok := s.ev.(Scorer); ok && s.scoreMapper != nil {
+		return s.scoreMapper(sc.Score(o.board, side) + s.handicapFor(side))
+	}
+	return s.sign(side, o.winner)
+}
@@ -0,0 +1,1220 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	moves   [][]Move
+	winners []int
+}
+
+func (rs *recordingSink) Record(moves []Move, winner int) {
+	rs.moves = append(rs.moves, moves)
+	rs.winners = append(rs.winners, winner)
+}
+
+func TestTrajectorySink(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	board := newBoard(3, 3)
+	iters := 200
+	s.Search(board, 1, time.Hour, 0, iters)
+	if len(rs.moves) != iters {
+		t.Fatalf("expected %v trajectories, got %v", iters, len(rs.moves))
+	}
+	for i, moves := range rs.moves {
+		b := copyBoard(board)
+		side := 1
+		winner := 0
+		for _, m := range moves {
+			gameOver, w, err := ev.ApplyMove(b, side, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gameOver {
+				winner = w
+			}
+			side = ev.NextPlayer(side)
+		}
+		if winner != rs.winners[i] {
+			t.Fatalf("trajectory %v: replayed winner %v, recorded winner %v", i, winner, rs.winners[i])
+		}
+	}
+}
+
+func newBoard(rows, cols int) [][]int {
+	board := make([][]int, rows)
+	for i := range board {
+		board[i] = make([]int, cols)
+	}
+	return board
+}
+
+// tttMove is a tictactoe move used in tests.
+type tttMove struct {
+	i, j int
+	side int
+	eval float64
+}
+
+func (m *tttMove) Eval() float64 {
+	return m.eval
+}
+
+func (m *tttMove) Apply(board [][]int) {
+	board[m.i][m.j] = m.side
+}
+
+func (m *tttMove) Revert(board [][]int) {
+	board[m.i][m.j] = 0
+}
+
+// tttEval implements both Evaluator and Expander for an n in a row game on any board size.
+type tttEval struct {
+	target int
+	r      *rand.Rand
+}
+
+func newTTTEval(target int, seed int64) *tttEval {
+	return &tttEval{target: target, r: rand.New(rand.NewSource(seed))}
+}
+
+func (e *tttEval) Expand(board [][]int, side int) []Move {
+	res := make([]Move, 0)
+	for i, row := range board {
+		for j, v := range row {
+			if v == 0 {
+				res = append(res, &tttMove{i: i, j: j, side: side})
+			}
+		}
+	}
+	return res
+}
+
+func (e *tttEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	moves := e.Expand(board, currentPlayerSide)
+	if len(moves) == 0 {
+		return nil
+	}
+	return moves[e.r.Intn(len(moves))]
+}
+
+func (e *tttEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	mov := m.(*tttMove)
+	board[mov.i][mov.j] = currentPlayerSide
+	if e.wins(board, mov.i, mov.j) {
+		return true, currentPlayerSide, nil
+	}
+	for _, row := range board {
+		for _, v := range row {
+			if v == 0 {
+				return false, 0, nil
+			}
+		}
+	}
+	return true, 0, nil
+}
+
+func (e *tttEval) wins(board [][]int, i, j int) bool {
+	side := board[i][j]
+	for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		count := 1
+		for _, sign := range []int{1, -1} {
+			r, c := i+sign*d[0], j+sign*d[1]
+			for r >= 0 && r < len(board) && c >= 0 && c < len(board[r]) && board[r][c] == side {
+				count++
+				r, c = r+sign*d[0], c+sign*d[1]
+			}
+		}
+		if count >= e.target {
+			return true
+		}
+	}
+	return false
+}
+
+// finished returns whether board is a finished game and its winner.
+func (e *tttEval) finished(board [][]int) (bool, int) {
+	for i, row := range board {
+		for j, v := range row {
+			if v != 0 && e.wins(board, i, j) {
+				return true, v
+			}
+		}
+	}
+	return len(e.Expand(board, 1)) == 0, 0
+}
+
+func (e *tttEval) NextPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (e *tttEval) PrevPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func TestSearchCaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		maxIters int
+		want     int
+	}{
+		{"iterations only", 0, 50, 50},
+		{"iterations before duration", time.Hour, 50, 50},
+		{"duration before iterations", time.Nanosecond, 1000, 1},
+		{"no caps", 0, 0, 1},
+		{"negative caps", -time.Second, -1, 1},
+	}
+	for _, tt := range tests {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		rs := &recordingSink{}
+		s.SetTrajectorySink(rs)
+		s.Search(newBoard(3, 3), 1, tt.duration, 0, tt.maxIters)
+		if len(rs.moves) != tt.want {
+			t.Errorf("%v: expected %v iterations, got %v", tt.name, tt.want, len(rs.moves))
+		}
+	}
+}
+
+func TestSearchDurationOnly(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	d := 20 * time.Millisecond
+	t0 := time.Now()
+	s.Search(newBoard(3, 3), 1, d, 0, 0)
+	if elapsed := time.Since(t0); elapsed < d {
+		t.Fatalf("search returned after %v, before the duration of %v", elapsed, d)
+	}
+	if len(rs.moves) < 1 {
+		t.Fatal("expected at least one iteration")
+	}
+}
+
+// countingEval is a tictactoe evaluator that keeps the move count in an extra last row
+// of the board.
+type countingEval struct {
+	*tttEval
+	clones int
+}
+
+func (e *countingEval) CloneState(board [][]int) [][]int {
+	e.clones++
+	res := copyBoard(board[:len(board)-1])
+	return append(res, []int{board[len(board)-1][0]})
+}
+
+func (e *countingEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	board[len(board)-1][0]++
+	return e.tttEval.ApplyMove(board[:len(board)-1], currentPlayerSide, m)
+}
+
+func (e *countingEval) Expand(board [][]int, side int) []Move {
+	return e.tttEval.Expand(board[:len(board)-1], side)
+}
+
+func (e *countingEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return e.tttEval.RandomMove(board[:len(board)-1], currentPlayerSide)
+}
+
+func TestCloneState(t *testing.T) {
+	ev := &countingEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	board := append(newBoard(3, 3), []int{0})
+	s.Search(board, 1, 0, 0, 100)
+	if ev.clones == 0 {
+		t.Fatal("expected CloneState to be used")
+	}
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if got := n.board[len(n.board)-1][0]; got != n.depth {
+			t.Fatalf("node at depth %v has move count %v", n.depth, got)
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(s.root)
+}
+
+func TestSearchFor(t *testing.T) {
+	// X wins with the only legal move.
+	board := [][]int{
+		{1, 2, 1},
+		{2, 1, 2},
+		{2, 1, 0},
+	}
+	ev := newTTTEval(3, 1)
+	m, v, _ := New(ev, ev).SearchFor(copyBoard(board), 1, 1, 0, 0, 50)
+	om, ov, _ := New(ev, ev).SearchFor(copyBoard(board), 1, 2, 0, 0, 50)
+	if m.(*tttMove).i != 2 || m.(*tttMove).j != 2 || om.(*tttMove).i != 2 || om.(*tttMove).j != 2 {
+		t.Fatal("expected the only legal move")
+	}
+	if v <= 0 {
+		t.Fatalf("expected a positive value for the winning side, got %v", v)
+	}
+	if ov != -v {
+		t.Fatalf("expected value %v for the opponent, got %v", -v, ov)
+	}
+}
+
+// playoutCounter counts RandomMove calls.
+type playoutCounter struct {
+	*tttEval
+	calls int
+}
+
+func (e *playoutCounter) RandomMove(board [][]int, currentPlayerSide int) Move {
+	e.calls++
+	return e.tttEval.RandomMove(board, currentPlayerSide)
+}
+
+func TestTerminalLeavesSkipPlayOut(t *testing.T) {
+	// both legal moves win for X.
+	board := [][]int{
+		{1, 2, 1},
+		{2, 1, 2},
+		{0, 1, 0},
+	}
+	ev := &playoutCounter{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	s.Search(board, 1, 0, 0, 50)
+	if ev.calls != 0 {
+		t.Fatalf("expected no playouts, got %v RandomMove calls", ev.calls)
+	}
+	if len(rs.moves) != 50 {
+		t.Fatalf("expected 50 recorded trajectories, got %v", len(rs.moves))
+	}
+	for i, w := range rs.winners {
+		if w != 1 || len(rs.moves[i]) != 1 {
+			t.Fatalf("expected single move wins for X, got %v moves with winner %v", len(rs.moves[i]), w)
+		}
+	}
+}
+
+// boardMove is a tictactoe move that carries its resulting board.
+type boardMove struct {
+	tttMove
+	board [][]int
+}
+
+func (m *boardMove) ResultingBoard() [][]int {
+	return m.board
+}
+
+// precomputedEval expands tictactoe moves with precomputed boards, counts ApplyMove calls
+// and has no rollout moves.
+type precomputedEval struct {
+	*tttEval
+	applied int
+}
+
+func (e *precomputedEval) Expand(board [][]int, side int) []Move {
+	res := make([]Move, 0)
+	for _, m := range e.tttEval.Expand(board, side) {
+		b := copyBoard(board)
+		b[m.(*tttMove).i][m.(*tttMove).j] = side
+		res = append(res, &boardMove{tttMove: *m.(*tttMove), board: b})
+	}
+	return res
+}
+
+func (e *precomputedEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return nil
+}
+
+func (e *precomputedEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	e.applied++
+	return e.tttEval.ApplyMove(board, currentPlayerSide, m)
+}
+
+func (e *precomputedEval) IsTerminal(board [][]int) (bool, int) {
+	return e.finished(board)
+}
+
+func TestBoardMoveSkipsApplyMove(t *testing.T) {
+	ev := &precomputedEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.Search(newBoard(3, 3), 1, 0, 0, 100)
+	if ev.applied != 0 {
+		t.Fatalf("expected no ApplyMove calls, got %v", ev.applied)
+	}
+	if len(s.root.children) != 9 {
+		t.Fatalf("expected 9 root children, got %v", len(s.root.children))
+	}
+	for _, ch := range s.root.children {
+		m := ch.move.(*boardMove)
+		if ch.board[m.i][m.j] != 1 {
+			t.Fatal("expected child board to contain the move")
+		}
+	}
+}
+
+func TestRolloutBalance(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if len(s.RolloutBalance()) != 0 {
+		t.Fatal("expected an empty balance before a search")
+	}
+	s.Search(newBoard(3, 3), 1, 0, 0, 2000)
+	total := 0.0
+	for w, f := range s.RolloutBalance() {
+		if w != 0 && f > 0.8 {
+			t.Fatalf("side %v won %v of the rollouts", w, f)
+		}
+		total += f
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("expected fractions to sum to 1, got %v", total)
+	}
+}
+
+// terminalEval is a tictactoe evaluator that detects finished games with IsTerminal.
+type terminalEval struct {
+	*tttEval
+}
+
+func (e *terminalEval) IsTerminal(board [][]int) (bool, int) {
+	return e.finished(board)
+}
+
+func TestSearchTerminalRoot(t *testing.T) {
+	// X has already won.
+	board := [][]int{
+		{1, 1, 1},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	ev := &terminalEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	m, visits := s.Search(board, 2, 0, 0, 100)
+	if m != nil || visits != 0 {
+		t.Fatalf("expected no move for a finished game, got %v with %v visits", m, visits)
+	}
+
+	// without a TerminalChecker a board without moves is terminal.
+	g := &marginGame{}
+	if m, visits := New(g, g).Search([][]int{{5, 1}}, 1, 0, 0, 100); m != nil || visits != 0 {
+		t.Fatalf("expected no move for a board without moves, got %v with %v visits", m, visits)
+	}
+}
+
+// hintedEval is a tictactoe evaluator that hints the maximum number of moves.
+type hintedEval struct {
+	*tttEval
+	maxMoves int
+}
+
+func (e *hintedEval) MaxMoves() int {
+	return e.maxMoves
+}
+
+func benchmarkExpand(b *testing.B, ev Evaluator, ex Expander) {
+	b.ReportAllocs()
+	board := newBoard(5, 5)
+	s := New(ev, ex)
+	for i := 0; i < b.N; i++ {
+		s.expand(&treeNode{board: board, side: 2}, 0)
+	}
+}
+
+func BenchmarkExpand(b *testing.B) {
+	ev := newTTTEval(4, 1)
+	benchmarkExpand(b, ev, ev)
+}
+
+func BenchmarkExpandMoveCountHint(b *testing.B) {
+	ev := &hintedEval{tttEval: newTTTEval(4, 1), maxMoves: 25}
+	benchmarkExpand(b, ev, ev)
+}
+
+func TestMoveCountHint(t *testing.T) {
+	ev := &hintedEval{tttEval: newTTTEval(3, 1), maxMoves: 9}
+	s := New(ev, ev)
+	m, _ := s.Search(newBoard(3, 3), 1, 0, 0, 200)
+	if m == nil || len(s.root.children) != 9 || cap(s.root.children) != 9 {
+		t.Fatal("expected 9 preallocated root children")
+	}
+	for _, ch := range s.root.children {
+		if ch.parent != s.root || ch.depth != 1 {
+			t.Fatal("expected slab allocated children to be linked to the root")
+		}
+	}
+}
+
+type passMove struct {
+	side int
+}
+
+func (m *passMove) Eval() float64 {
+	return 0
+}
+
+// passGame is a game where the only move is passing, which never ends the game by itself.
+type passGame struct{}
+
+func (g *passGame) Expand(board [][]int, side int) []Move {
+	return []Move{g.PassMove(side)}
+}
+
+func (g *passGame) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return g.PassMove(currentPlayerSide)
+}
+
+func (g *passGame) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	board[0][0]++
+	return false, 0, nil
+}
+
+func (g *passGame) NextPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (g *passGame) PrevPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (g *passGame) PassMove(side int) Move {
+	return &passMove{side: side}
+}
+
+func TestConsecutivePassesEndGame(t *testing.T) {
+	g := &passGame{}
+	s := New(g, g)
+	s.SetKeepSearchingAfterProof(true)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	m, _ := s.Search([][]int{{0}}, 1, 0, 0, 50)
+	if m.(*passMove).side != 1 {
+		t.Fatal("expected a pass of player 1")
+	}
+	if len(rs.moves) != 50 {
+		t.Fatalf("expected 50 rollouts, got %v", len(rs.moves))
+	}
+	for i, moves := range rs.moves {
+		if len(moves) != 2 || rs.winners[i] != 0 {
+			t.Fatalf("expected games to end in a draw after two passes, got %v moves with winner %v", len(moves), rs.winners[i])
+		}
+	}
+	for _, ch := range s.root.children[0].children {
+		if !ch.gameOver || ch.winner != 0 {
+			t.Fatal("expected the second pass in the tree to end the game as a draw")
+		}
+	}
+}
+
+// doubleMoveGame is a game where player 1 moves twice in a row, encoded as sides 1 and 11,
+// and wins only if both moves pick 2. Otherwise player 2 wins.
+type doubleMoveGame struct {
+	r *rand.Rand
+}
+
+func (g *doubleMoveGame) Expand(board [][]int, side int) []Move {
+	for cell, v := range board[0] {
+		if v == 0 {
+			return []Move{&marginMove{cell: cell, val: 1}, &marginMove{cell: cell, val: 2}}
+		}
+	}
+	return nil
+}
+
+func (g *doubleMoveGame) RandomMove(board [][]int, currentPlayerSide int) Move {
+	moves := g.Expand(board, currentPlayerSide)
+	if len(moves) == 0 {
+		return nil
+	}
+	return moves[g.r.Intn(len(moves))]
+}
+
+func (g *doubleMoveGame) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	mov := m.(*marginMove)
+	board[0][mov.cell] = mov.val
+	if board[0][1] == 0 {
+		return false, 0, nil
+	}
+	if board[0][0] == 2 && board[0][1] == 2 {
+		return true, 1, nil
+	}
+	return true, 2, nil
+}
+
+func (g *doubleMoveGame) NextPlayer(currentPlayerSide int) int {
+	return map[int]int{1: 11, 11: 2, 2: 1}[currentPlayerSide]
+}
+
+func (g *doubleMoveGame) PrevPlayer(currentPlayerSide int) int {
+	return map[int]int{1: 2, 11: 1, 2: 11}[currentPlayerSide]
+}
+
+func TestValueSign(t *testing.T) {
+	secondMove := func(s *MCTS) *treeNode {
+		s.SetKeepSearchingAfterProof(true)
+		s.Search(newBoard(1, 2), 1, 0, 0, 200)
+		for _, ch := range s.root.children {
+			if ch.move.(*marginMove).val != 2 {
+				continue
+			}
+			for _, gch := range ch.children {
+				if gch.move.(*marginMove).val == 2 {
+					return gch
+				}
+			}
+		}
+		t.Fatal("expected the winning line in the tree")
+		return nil
+	}
+	g := &doubleMoveGame{r: rand.New(rand.NewSource(1))}
+	if n := secondMove(New(g, g)); n.value() >= 0 {
+		t.Fatalf("expected the default sign to misattribute the win of side 11, got value %v", n.value())
+	}
+	s := New(g, g)
+	s.SetValueSign(func(nodeSide, winner int) float64 {
+		if winner == 0 {
+			return 0
+		}
+		if nodeSide%10 == winner {
+			return 1
+		}
+		return -1
+	})
+	if n := secondMove(s); n.value() <= 0 {
+		t.Fatalf("expected a positive value for the winning second move, got %v", n.value())
+	}
+	if m, _ := s.Search(newBoard(1, 2), 1, 0, 0, 200); m.(*marginMove).val != 2 {
+		t.Fatal("expected player 1 to start the winning line")
+	}
+}
+
+func TestDefaultIterations(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	s.SetDefaultIterations(300)
+	s.Search(newBoard(3, 3), 1, 0, 0, 0)
+	if len(rs.moves) != 300 {
+		t.Fatalf("expected 300 default iterations, got %v", len(rs.moves))
+	}
+	rs.moves = nil
+	s.Search(newBoard(3, 3), 1, 0, 0, 20)
+	if len(rs.moves) != 20 {
+		t.Fatalf("expected maxIters to take precedence, got %v iterations", len(rs.moves))
+	}
+	rs.moves = nil
+	s.SetDefaultIterations(0)
+	s.Search(newBoard(3, 3), 1, 0, 0, 0)
+	if len(rs.moves) != 1 {
+		t.Fatalf("expected a single iteration without defaults, got %v", len(rs.moves))
+	}
+}
+
+func TestDepthExploration(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetDepthExploration(func(depth int) float64 {
+		if depth > 2 {
+			return 0
+		}
+		return 1
+	})
+	// the second child has a higher value but many more visits than the first one
+	node := func(depth int) *treeNode {
+		n := &treeNode{depth: depth, visits: 1000}
+		n.children = []*treeNode{
+			{parent: n, depth: depth + 1, visits: 2, winScore: 0},
+			{parent: n, depth: depth + 1, visits: 998, winScore: 499},
+		}
+		return n
+	}
+	shallow, deep := node(0), node(2)
+	if s.highestUCBChild(shallow) != shallow.children[0] {
+		t.Fatal("expected exploration to select the rarely visited child near the root")
+	}
+	if s.highestUCBChild(deep) != deep.children[1] {
+		t.Fatal("expected pure exploitation beyond depth 2")
+	}
+}
+
+// concentration returns the share of the most visited child in the visits of all children of n.
+func concentration(n *treeNode) float64 {
+	var max, total int64
+	for _, ch := range n.children {
+		total += ch.visits
+		if ch.visits > max {
+			max = ch.visits
+		}
+	}
+	return float64(max) / float64(total)
+}
+
+func TestExplorationBySide(t *testing.T) {
+	run := func(bySide map[int]float64) (float64, float64) {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		s.SetExplorationBySide(bySide)
+		s.Search(newBoard(3, 3), 1, 0, 0, 2000)
+		return concentration(s.root), concentration(bestChild(s.root))
+	}
+	xLow, oHigh := run(map[int]float64{1: 0.1, 2: 3})
+	xHigh, oLow := run(map[int]float64{1: 3, 2: 0.1})
+	if xLow <= xHigh {
+		t.Fatalf("expected X moves to concentrate more with low exploration, got %v and %v", xLow, xHigh)
+	}
+	if oLow <= oHigh {
+		t.Fatalf("expected O moves to concentrate more with low exploration, got %v and %v", oLow, oHigh)
+	}
+}
+
+// duplicatingEval is a tictactoe evaluator whose Expand returns every move twice.
+type duplicatingEval struct {
+	*tttEval
+}
+
+func (e *duplicatingEval) Expand(board [][]int, side int) []Move {
+	moves := e.tttEval.Expand(board, side)
+	return append(moves, e.tttEval.Expand(board, side)...)
+}
+
+func TestDedupMoves(t *testing.T) {
+	ev := &duplicatingEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.Search(newBoard(3, 3), 1, 0, 0, 100)
+	if len(s.root.children) != 18 {
+		t.Fatalf("expected duplicate children without dedup, got %v", len(s.root.children))
+	}
+	s = New(ev, ev)
+	s.SetDedupMoves(true)
+	s.Search(newBoard(3, 3), 1, 0, 0, 100)
+	if len(s.root.children) != 9 {
+		t.Fatalf("expected 9 distinct children, got %v", len(s.root.children))
+	}
+	for _, ch := range s.root.children {
+		if len(ch.children) != 0 && len(ch.children) != 8 {
+			t.Fatalf("expected 8 distinct grandchildren, got %v", len(ch.children))
+		}
+	}
+}
+
+// naiveHighestUCBChild computes the UCB value of every child of n from scratch.
+func naiveHighestUCBChild(s *MCTS, n *treeNode) *treeNode {
+	parentVisits := float64(n.visits)
+	c := s.exploration(n)
+	var res *treeNode
+	var maxVal float64
+	for _, node := range n.children {
+		if node.visits == 0 {
+			return node
+		}
+		val := s.exploitation(node) + c*math.Sqrt(math.Log(parentVisits)/s.selectionVisits(node))
+		if res == nil || val > maxVal {
+			maxVal = val
+			res = node
+		}
+	}
+	return res
+}
+
+func TestCachedUCBMatchesNaive(t *testing.T) {
+	for name, setup := range map[string]func(s *MCTS){
+		"average": func(s *MCTS) {},
+		"minimax": func(s *MCTS) { s.SetBackupMode(Minimax) },
+		"shared":  func(s *MCTS) { s.SetSharedStats(true) },
+	} {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		s.SetKeepSearchingAfterProof(true)
+		setup(s)
+		s.SetTreeReuse(true)
+		for i := 0; i < 5; i++ {
+			s.SearchIterations(newBoard(3, 3), 1, 300)
+			var walk func(n *treeNode)
+			walk = func(n *treeNode) {
+				if len(n.children) == 0 {
+					return
+				}
+				if s.highestUCBChild(n) != naiveHighestUCBChild(s, n) {
+					t.Fatalf("%v: expected the cached selection to match the naive one", name)
+				}
+				for _, ch := range n.children {
+					walk(ch)
+				}
+			}
+			walk(s.root)
+		}
+	}
+}
+
+// wideTree returns a root with branching children of random statistics.
+func wideTree(branching int) *treeNode {
+	r := rand.New(rand.NewSource(1))
+	root := &treeNode{side: 2}
+	for i := 0; i < branching; i++ {
+		visits := int64(1 + r.Intn(100))
+		root.children = append(root.children, &treeNode{
+			parent:   root,
+			side:     1,
+			visits:   visits,
+			winScore: r.Float64() * float64(visits),
+		})
+		root.visits += visits
+	}
+	return root
+}
+
+func BenchmarkHighestUCBChild(b *testing.B) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.ucbGen = 1
+	root := wideTree(400)
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveHighestUCBChild(s, root)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.highestUCBChild(root)
+		}
+	})
+}
+
+// narrowingEval is a tictactoe evaluator that expands only two moves from depth 2 on.
+type narrowingEval struct {
+	*tttEval
+}
+
+func (e *narrowingEval) ExpandAt(board [][]int, side, depth int) []Move {
+	moves := e.Expand(board, side)
+	if depth >= 2 && len(moves) > 2 {
+		moves = moves[:2]
+	}
+	return moves
+}
+
+func TestDepthExpander(t *testing.T) {
+	ev := &narrowingEval{tttEval: newTTTEval(4, 1)}
+	s := New(ev, ev)
+	s.SearchIterations(newBoard(4, 4), 1, 2000)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		switch {
+		case n.depth < 2 && len(n.children) != 0 && len(n.children) != 16-n.depth:
+			t.Fatalf("expected all moves at depth %v, got %v", n.depth, len(n.children))
+		case n.depth >= 2 && len(n.children) > 2:
+			t.Fatalf("expected at most 2 moves at depth %v, got %v", n.depth, len(n.children))
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(s.root)
+	if len(s.root.children[0].children) != 15 {
+		t.Fatal("expected the root children to be expanded")
+	}
+}
+
+func TestExpansionThreshold(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.SetExpansionThreshold(10)
+	s.SearchIterations(newBoard(4, 4), 1, 1)
+	leaf := s.root.children[0]
+	for leaf.visits < 10 {
+		s.expand(leaf, 0)
+		if len(leaf.children) != 0 {
+			t.Fatalf("expected no expansion with %v visits", leaf.visits)
+		}
+		leaf.visits++
+	}
+	s.expand(leaf, 0)
+	if len(leaf.children) != 15 {
+		t.Fatalf("expected an expansion with 10 visits, got %v children", len(leaf.children))
+	}
+
+	s.SearchIterations(newBoard(4, 4), 1, 1000)
+	small := countNodes(s.root)
+	s = New(ev, ev)
+	s.SearchIterations(newBoard(4, 4), 1, 1000)
+	if small*2 > countNodes(s.root) {
+		t.Fatalf("expected a much smaller tree with a threshold, got %v and %v nodes", small, countNodes(s.root))
+	}
+}
+
+// rotatingGame is a three player tictactoe whose PrevPlayer is not the inverse of NextPlayer.
+type rotatingGame struct {
+	*tttEval
+}
+
+func (g *rotatingGame) NextPlayer(currentPlayerSide int) int {
+	return currentPlayerSide%3 + 1
+}
+
+func (g *rotatingGame) PrevPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func TestRootSideToMove(t *testing.T) {
+	g := &rotatingGame{tttEval: newTTTEval(3, 1)}
+	s := New(g, g)
+	s.SearchIterations(newBoard(4, 4), 1, 300)
+	for _, ch := range s.root.children {
+		if ch.side != 1 || ch.move.(*tttMove).side != 1 {
+			t.Fatalf("expected the root to be expanded for player 1, got %v", ch.side)
+		}
+		for _, gch := range ch.children {
+			if gch.side != 2 {
+				t.Fatalf("expected the root children to be expanded for player 2, got %v", gch.side)
+			}
+			for _, ggch := range gch.children {
+				if ggch.side != 3 {
+					t.Fatalf("expected player 3 after player 2, got %v", ggch.side)
+				}
+			}
+		}
+	}
+}
+
+func TestRootSidePerspective(t *testing.T) {
+	// a new root takes the perspective of a reused root of the same position, so that their
+	// statistics compare, which is why the side of a new root is PrevPlayer of its side to move
+	ev := newTTTEval(3, 1)
+	reused := New(ev, ev)
+	reused.SetTreeReuse(true)
+	reused.SearchIterations(newBoard(3, 3), 1, 300)
+	board := newBoard(3, 3)
+	board[1][1] = 1
+	reused.SearchIterations(copyBoard(board), 2, 300)
+	if reused.root.iters <= 300 {
+		t.Fatal("expected the root to be reused")
+	}
+	fresh := New(ev, ev)
+	fresh.SearchIterations(copyBoard(board), 2, 300)
+	if reused.root.side != 1 || fresh.root.side != reused.root.side {
+		t.Fatalf("expected the perspective of player 1 at both roots, got %v and %v", reused.root.side, fresh.root.side)
+	}
+	if reused.root.toMove != 2 || fresh.root.toMove != 2 {
+		t.Fatal("expected player 2 to move at both roots")
+	}
+}
+
+func TestRootExploration(t *testing.T) {
+	explored := func(rootC float64) int {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		s.SetExploration(0.1)
+		s.SetRootExploration(rootC)
+		s.SearchIterations(newBoard(4, 4), 1, 100)
+		res := 0
+		for _, ch := range s.root.children {
+			if ch.iters >= 3 {
+				res++
+			}
+		}
+		return res
+	}
+	low, high := explored(0), explored(5)
+	if high != 16 || low >= high {
+		t.Fatalf("expected a high root exploration to explore all root moves sooner, got %v and %v of 16", low, high)
+	}
+}
+
+func TestValueSmoothing(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	change := func(s *MCTS) float64 {
+		n := &treeNode{visits: 1, winScore: 1}
+		before := s.exploitation(n)
+		n.visits++
+		n.winScore--
+		return math.Abs(s.exploitation(n) - before)
+	}
+	raw := change(New(ev, ev))
+	s := New(ev, ev)
+	s.SetValueSmoothing(0, 4)
+	smoothed := change(s)
+	if raw != 1 || smoothed >= raw/2 {
+		t.Fatalf("expected smoothing to dampen a low visit value change, got %v and %v", raw, smoothed)
+	}
+	n := &treeNode{visits: 10000, winScore: 5000}
+	if v := s.exploitation(n); math.Abs(v-0.5) > 0.001 {
+		t.Fatalf("expected a negligible effect on a high visit node, got %v", v)
+	}
+}
+
+func TestCooperativeYield(t *testing.T) {
+	calls := 0
+	defer func(f func()) { yield = f }(yield)
+	yield = func() { calls++ }
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.SearchIterations(newBoard(4, 4), 1, 100)
+	if calls != 0 {
+		t.Fatalf("expected no yields by default, got %v", calls)
+	}
+	s.SetCooperativeYield(10)
+	s.SearchIterations(newBoard(4, 4), 1, 105)
+	if calls != 10 {
+		t.Fatalf("expected 10 yields, got %v", calls)
+	}
+}
+
+func TestPreExpandRoot(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.SetPreExpandRoot(true)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	s.SearchIterations(newBoard(4, 4), 1, 1)
+	if len(s.root.children) != 16 {
+		t.Fatalf("expected all 16 root moves, got %v", len(s.root.children))
+	}
+	for _, ch := range s.root.children {
+		if ch.iters == 0 {
+			t.Fatal("expected a rollout from every root move")
+		}
+	}
+	if len(rs.moves) != 17 {
+		t.Fatalf("expected 16 rollouts before the iteration, got %v", len(rs.moves)-1)
+	}
+}
+
+func TestUCBHugeVisits(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	for _, rootVisits := range []int64{math.MaxInt64, 0, math.MinInt64} {
+		root := &treeNode{board: newBoard(3, 3), side: 2, visits: rootVisits}
+		for i, mean := range []float64{0.1, 0.5, 0.3} {
+			visits := int64(math.MaxInt64/4) + int64(i)
+			root.children = append(root.children, &treeNode{
+				parent:   root,
+				side:     1,
+				visits:   visits,
+				winScore: mean * float64(visits),
+			})
+		}
+		if ch := s.highestUCBChild(root); ch != root.children[1] {
+			t.Fatalf("expected the highest mean child with %v root visits", rootVisits)
+		}
+		for _, ch := range root.children {
+			mean, inv := s.ucbTerms(ch)
+			if ucb := mean + s.explorationTerm(root)*inv; math.IsNaN(ucb) || math.IsInf(ucb, 0) {
+				t.Fatalf("expected a finite UCB value with %v root visits, got %v", rootVisits, ucb)
+			}
+		}
+	}
+}
+
+func TestSearchWithRootMoves(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	moves := []Move{
+		&tttMove{i: 0, j: 0, side: 1, eval: 0.5},
+		&tttMove{i: 1, j: 1, side: 1},
+		&tttMove{i: 2, j: 1, side: 1, eval: -0.5},
+	}
+	m, _ := s.SearchWithRootMoves(newBoard(3, 3), 1, moves, SearchBudget{MaxIters: 300})
+	if len(s.root.children) != len(moves) {
+		t.Fatalf("expected %v root children, got %v", len(moves), len(s.root.children))
+	}
+	deeper := false
+	for i, ch := range s.root.children {
+		if ch.move != moves[i] {
+			t.Fatalf("expected root child %v to be the provided move", i)
+		}
+		deeper = deeper || len(ch.children) == 8
+	}
+	if !deeper {
+		t.Fatal("expected the Expander to expand deeper nodes")
+	}
+	if m != moves[0] && m != moves[1] && m != moves[2] {
+		t.Fatalf("expected one of the provided moves, got %v", m)
+	}
+	if m, _ := s.SearchWithRootMoves(newBoard(3, 3), 1, nil, SearchBudget{MaxIters: 10}); m == nil || len(s.root.children) != 9 {
+		t.Fatal("expected the Expander to expand the root without moves")
+	}
+}
+
+func TestSelectionTieBreak(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	root := &treeNode{board: newBoard(3, 3), side: 2, toMove: 1, visits: 20}
+	for _, eval := range []float64{0.1, 0.3} {
+		root.children = append(root.children, &treeNode{
+			parent:   root,
+			side:     1,
+			depth:    1,
+			move:     &tttMove{eval: eval},
+			visits:   10,
+			winScore: 5,
+		})
+	}
+	picks := func(tb TieBreak) map[*treeNode]int {
+		s := New(ev, ev)
+		s.SetSelectionRand(rand.New(rand.NewSource(1)))
+		s.SetSelectionTieBreak(tb)
+		res := make(map[*treeNode]int)
+		for i := 0; i < 100; i++ {
+			res[s.highestUCBChild(root)]++
+		}
+		return res
+	}
+	if p := picks(FirstTie); p[root.children[0]] != 100 {
+		t.Fatalf("expected the first child by default, got %v", p)
+	}
+	if p := picks(RandomTie); p[root.children[0]] < 20 || p[root.children[1]] < 20 {
+		t.Fatalf("expected random tie breaks to select both children, got %v", p)
+	}
+	if p := picks(PriorTie); p[root.children[1]] != 100 {
+		t.Fatalf("expected the child with the higher prior, got %v", p)
+	}
+}
+
+func TestMoveFilter(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	// only the top row at the root and anything but the center deeper
+	s.SetMoveFilter(func(m Move, depth int) bool {
+		mov := m.(*tttMove)
+		if depth == 1 {
+			return mov.i == 0
+		}
+		return mov.i != 1 || mov.j != 1
+	})
+	s.SearchIterations(newBoard(3, 3), 1, 1000)
+	if len(s.root.children) != 3 {
+		t.Fatalf("expected 3 root children, got %v", len(s.root.children))
+	}
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		for _, ch := range n.children {
+			mov := ch.move.(*tttMove)
+			if ch.depth == 1 && mov.i != 0 || mov.i == 1 && mov.j == 1 {
+				t.Fatalf("expected the filtered move %v to stay out of the tree at depth %v", mov, ch.depth)
+			}
+			walk(ch)
+		}
+	}
+	walk(s.root)
+	if countNodes(s.root) < 100 {
+		t.Fatal("expected the allowed subtree to be searched")
+	}
+}
+
+func TestMoveFilterProof(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	// the filtered move (0, 2) wins, and the allowed moves draw at best
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{1, 2, 0},
+	}
+	s.SetMoveFilter(func(m Move, depth int) bool {
+		mov := m.(*tttMove)
+		return depth > 1 || mov.i != 0 || mov.j != 2
+	})
+	s.SearchIterations(board, 1, 200)
+	for _, ch := range s.root.children {
+		if !ch.proven {
+			t.Fatalf("expected the allowed move %v to be proven", ch.move)
+		}
+	}
+	if s.root.proven {
+		t.Fatalf("expected the root with a filtered move to stay unproven, got a proven win of %v", s.root.provenWinner)
+	}
+}
+
+func TestForcedMoves(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	depths := make(map[int]int)
+	s.SetDepthExploration(func(depth int) float64 {
+		depths[depth]++
+		return 1
+	})
+	// both moves of side 1 leave a single reply
+	board := [][]int{{1, 2, 1}, {1, 2, 2}, {2, 0, 0}}
+	s.Search(board, 1, 0, 0, 100)
+	if depths[1] == 0 {
+		t.Fatal("expected UCB values of the root children")
+	}
+	if depths[2] != 0 {
+		t.Fatalf("expected forced replies to be selected without UCB values, got %v", depths[2])
+	}
+	for _, ch := range s.root.children {
+		if len(ch.children) != 1 || ch.children[0].visits < 2 {
+			t.Fatalf("expected the forced reply to be searched, got %v children", len(ch.children))
+		}
+	}
+}
+
+func TestForcedMoveCredit(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetForcedMoveCredit(true)
+	board := [][]int{{1, 2, 1}, {1, 2, 2}, {2, 0, 0}}
+	s.Search(board, 1, 0, 0, 100)
+	for _, ch := range s.root.children {
+		reply := ch.children[0]
+		if got, want := s.exploitation(ch), -reply.value(); got != want {
+			t.Fatalf("expected the forced move to be credited with %v, got %v", want, got)
+		}
+	}
+	if m, _ := s.CurrentBest(); m.(*tttMove).i != 2 || m.(*tttMove).j != 1 {
+		t.Fatalf("expected the move that avoids the forced loss, got %+v", m)
+	}
+}
+
+func TestInitialValue(t *testing.T) {
+	// tried returns the number of root moves with a rollout after a short greedy search
+	tried := func(s *MCTS) int {
+		s.SetExploration(0.1)
+		s.Search(newBoard(4, 4), 1, 0, 0, 16)
+		res := 0
+		for _, ch := range s.root.children {
+			if ch.iters > 0 {
+				res++
+			}
+		}
+		return res
+	}
+	ev := newTTTEval(4, 1)
+	plain := tried(New(ev, ev))
+	s := New(ev, ev)
+	s.SetInitialValue(1)
+	if optimistic := tried(s); optimistic != 16 || optimistic <= plain {
+		t.Fatalf("expected optimistic values to try every move before %v, got %v", plain, optimistic)
+	}
+	s = New(ev, ev)
+	s.SetInitialValue(-1)
+	if pessimistic := tried(s); pessimistic > plain {
+		t.Fatalf("expected pessimistic values to try fewer moves than %v, got %v", plain, pessimistic)
+	}
+	s.SetInitialValue(math.NaN())
+	if restored := tried(s); restored != plain {
+		t.Fatalf("expected NaN to restore the default of %v tried moves, got %v", plain, restored)
+	}
+
+	// a warm started move has statistics before its first rollout
+	prev := New(ev, ev)
+	prev.Search(newBoard(4, 4), 1, 0, 0, 500)
+	s = New(ev, ev)
+	s.SetInitialValue(1)
+	s.WarmStart(prev)
+	s.SearchIterations(newBoard(4, 4), 1, 1)
+	for _, ch := range s.root.children {
+		if ch.iters == 0 && s.exploitation(ch) == 1 {
+			t.Fatalf("expected the warm started move %v to use its statistics", ch.move)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package mcts
+
+import "unsafe"
+
+// SetMemoryBudget sets an approximate limit in bytes for the memory used by the search tree.
+// Once the estimated size of the tree reaches the budget, nodes are no longer expanded and the
+// remaining iterations refine the existing tree with rollouts. The last expansion may exceed
+// the budget by the size of the children it adds. A budget less than or equal to 0, which is
+// the default, disables the limit.
+func (s *MCTS) SetMemoryBudget(bytes int64) {
+	s.memBudget = bytes
+}
+
+// EstimatedMemory returns the estimated size in bytes of the current search tree.
+func (s *MCTS) EstimatedMemory() int64 {
+	return s.memUsed
+}
+
+// nodeFootprint estimates the bytes used by n, including its board and its pointer in the
+// children of its parent. Boards usually dominate the size of the tree.
+func nodeFootprint(n *treeNode) int64 {
+	size := int64(unsafe.Sizeof(*n)) + int64(unsafe.Sizeof(n))
+	for _, row := range n.board {
+		size += int64(unsafe.Sizeof(row)) + int64(len(row))*int64(unsafe.Sizeof(0))
+	}
+	return size
+}
+
+// treeFootprint estimates the bytes used by the tree rooted at n.
+func treeFootprint(n *treeNode) int64 {
+	size := nodeFootprint(n)
+	for _, ch := range n.children {
+		size += treeFootprint(ch)
+	}
+	return size
+}
@@ -0,0 +1,36 @@
+package mcts
+
+import "testing"
+
+func countNodes(n *treeNode) int {
+	c := 1
+	for _, ch := range n.children {
+		c += countNodes(ch)
+	}
+	return c
+}
+
+func TestMemoryBudget(t *testing.T) {
+	ev := newTTTEval(5, 1)
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	budget := int64(400 * 1024)
+	s.SetMemoryBudget(budget)
+	board := newBoard(10, 10)
+	s.Search(board, 1, 0, 0, 200)
+	nodes := countNodes(s.root)
+	used := s.EstimatedMemory()
+	if nodes <= 101 {
+		t.Fatalf("expected the tree to grow beyond the root expansion, got %v nodes", nodes)
+	}
+	if used < budget || used > budget+100*nodeFootprint(s.root) {
+		t.Fatalf("expected estimated memory close to the budget of %v, got %v", budget, used)
+	}
+	s.Search(board, 1, 0, 0, 200)
+	if n := countNodes(s.root); n != nodes {
+		t.Fatalf("expected the tree to stop growing at %v nodes, got %v", nodes, n)
+	}
+	if s.root.visits < 400 {
+		t.Fatalf("expected the search to keep refining the tree, got %v root visits", s.root.visits)
+	}
+}
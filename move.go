@@ -1,5 +1,7 @@
 package mcts
 
+import "reflect"
+
 // Move is a move that can be applied to a board.
 // The Expander that lists the Moves to investigate can choose to return
 // an evaluation for each Move, which will be backpropagated to parent
@@ -9,3 +11,66 @@ package mcts
 type Move interface {
 	Eval() float64
 }
+
+// BoardMove is an optional interface for Moves that already know the board they result in,
+// for example because the Expander computed it while generating moves.
+// If the Evaluator implements TerminalChecker, expansion uses ResultingBoard together with
+// IsTerminal instead of applying the Move with ApplyMove.
+// The returned board is stored in the tree and must not be modified afterwards.
+type BoardMove interface {
+	ResultingBoard() [][]int
+}
+
+// SetMoveEqual sets the function that decides whether two Moves are the same Move,
+// for example when statistics of different trees are combined.
+// The default compares Moves with reflect.DeepEqual, so pointers to equal values match.
+func (s *MCTS) SetMoveEqual(f func(a, b Move) bool) {
+	s.moveEqual = f
+}
+
+func defaultMoveEqual(a, b Move) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// isPass reports whether m is the pass Move of side.
+func (s *MCTS) isPass(m Move, side int) bool {
+	p, ok := s.ev.(Passer)
+	if !ok || m == nil {
+		return false
+	}
+	return s.moveEqual(m, p.PassMove(side))
+}
+
+// DeltaMove is an optional interface for Moves that change only a small part of a board,
+// like a single cell. Apply must make the same board changes as ApplyMove and Revert must
+// undo them. When compact nodes are enabled, tree nodes of DeltaMoves do not store a board
+// and it is rebuilt from the path to the nearest stored board when needed.
+type DeltaMove interface {
+	Apply(board [][]int)
+	Revert(board [][]int)
+}
+
+// SetDedupMoves sets whether duplicate Moves returned by Expand are collapsed into a single
+// child, using the Move equality of the search. Duplicates would otherwise split the statistics
+// of a Move between several children. It is disabled by default.
+func (s *MCTS) SetDedupMoves(dedup bool) {
+	s.dedupMoves = dedup
+}
+
+// distinctMoves returns moves without duplicates, keeping the first occurrence of each Move.
+func (s *MCTS) distinctMoves(moves []Move) []Move {
+	res := moves[:0:0]
+	for _, m := range moves {
+		dup := false
+		for _, r := range res {
+			if s.moveEqual(m, r) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = append(res, m)
+		}
+	}
+	return res
+}
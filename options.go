@@ -0,0 +1,87 @@
+package mcts
+
+import "math/rand"
+
+// Option configures an MCTS structure created with NewWithOptions.
+// Every option is equivalent to the setter of the same name.
+type Option func(s *MCTS)
+
+// NewWithOptions returns a new MCTS structure like New, configured with opts in order.
+func NewWithOptions(ev Evaluator, ex Expander, opts ...Option) *MCTS {
+	s := New(ev, ex)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithExploration sets the exploration constant, see SetExploration.
+func WithExploration(c float64) Option {
+	return func(s *MCTS) {
+		s.SetExploration(c)
+	}
+}
+
+// WithPlayoutRand sets the playout random number generator, see SetPlayoutRand.
+func WithPlayoutRand(r *rand.Rand) Option {
+	return func(s *MCTS) {
+		s.SetPlayoutRand(r)
+	}
+}
+
+// WithSelectionRand sets the random number generator that breaks ties of the final move, see
+// SetSelectionRand.
+func WithSelectionRand(r *rand.Rand) Option {
+	return func(s *MCTS) {
+		s.SetSelectionRand(r)
+	}
+}
+
+// WithDiscount sets the reward discount factor, see SetDiscount.
+func WithDiscount(gamma float64) Option {
+	return func(s *MCTS) {
+		s.SetDiscount(gamma)
+	}
+}
+
+// WithDrawReward sets the reward of a draw, see SetDrawReward.
+func WithDrawReward(r float64) Option {
+	return func(s *MCTS) {
+		s.SetDrawReward(r)
+	}
+}
+
+// WithDrawSentinel sets the winner that stands for a draw, see SetDrawSentinel.
+func WithDrawSentinel(w int) Option {
+	return func(s *MCTS) {
+		s.SetDrawSentinel(w)
+	}
+}
+
+// WithBackupMode sets the backup mode, see SetBackupMode.
+func WithBackupMode(mode BackupMode) Option {
+	return func(s *MCTS) {
+		s.SetBackupMode(mode)
+	}
+}
+
+// WithPlayoutPolicy sets the playout policy, see SetPlayoutPolicy.
+func WithPlayoutPolicy(p PlayoutPolicy) Option {
+	return func(s *MCTS) {
+		s.SetPlayoutPolicy(p)
+	}
+}
+
+// WithTreeReuse sets whether trees are reused between searches, see SetTreeReuse.
+func WithTreeReuse(reuse bool) Option {
+	return func(s *MCTS) {
+		s.SetTreeReuse(reuse)
+	}
+}
+
+// WithDefaultIterations sets the default number of iterations, see SetDefaultIterations.
+func WithDefaultIterations(iters int) Option {
+	return func(s *MCTS) {
+		s.SetDefaultIterations(iters)
+	}
+}
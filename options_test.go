@@ -0,0 +1,68 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	// every game is won by player 1 after 2 plies
+	g := &marginGame{r: rand.New(rand.NewSource(1))}
+	if v, _ := NewWithOptions(g, g).Evaluate(newBoard(1, 2), 1, SearchBudget{MaxIters: 50}); v != 1 {
+		t.Fatalf("expected an undiscounted value of 1, got %v", v)
+	}
+	s := NewWithOptions(g, g, WithDiscount(0.5))
+	if v, _ := s.Evaluate(newBoard(1, 2), 1, SearchBudget{MaxIters: 50}); v != 0.25 {
+		t.Fatalf("expected a value discounted over 2 plies, got %v", v)
+	}
+
+	// the only move left draws
+	board := [][]int{
+		{1, 2, 1},
+		{1, 2, 2},
+		{2, 1, 0},
+	}
+	ev := newTTTEval(3, 1)
+	s = NewWithOptions(ev, ev, WithDrawReward(-0.3))
+	if v, _ := s.Evaluate(board, 1, SearchBudget{MaxIters: 10}); v != -0.3 {
+		t.Fatalf("expected the draw reward, got %v", v)
+	}
+	s = NewWithOptions(ev, ev, WithDefaultIterations(10))
+	if s.Search(newBoard(3, 3), 1, 0, 0, 0); s.rollouts != 10 {
+		t.Fatalf("expected the default iterations, got %v", s.rollouts)
+	}
+
+	spread := func(c float64) float64 {
+		s := NewWithOptions(ev, ev, WithExploration(c))
+		s.SearchIterations(newBoard(3, 3), 1, 2000)
+		lo, hi := math.Inf(1), 0.0
+		for _, ch := range s.root.children {
+			lo = math.Min(lo, float64(ch.visits))
+			hi = math.Max(hi, float64(ch.visits))
+		}
+		return hi / lo
+	}
+	if narrow, wide := spread(0.1), spread(100); narrow <= 2*wide {
+		t.Fatalf("expected a low exploration to concentrate visits, got spreads %v and %v", narrow, wide)
+	}
+
+	run := func(seed int64) [][]Move {
+		s := NewWithOptions(ev, ev, WithPlayoutPolicy(&uniformPolicy{ev: ev}), WithPlayoutRand(rand.New(rand.NewSource(seed))))
+		rs := &recordingSink{}
+		s.SetTrajectorySink(rs)
+		s.SearchIterations(newBoard(3, 3), 1, 100)
+		return rs.moves
+	}
+	if !reflect.DeepEqual(run(1), run(1)) || reflect.DeepEqual(run(1), run(2)) {
+		t.Fatal("expected the rollouts to follow the random number generator")
+	}
+	if s := NewWithOptions(ev, ev, WithPlayoutRand(rand.New(rand.NewSource(1)))); s.selectionRand != nil {
+		t.Fatal("expected the playout random number generator not to break final move ties")
+	}
+	r := rand.New(rand.NewSource(1))
+	if s := NewWithOptions(ev, ev, WithSelectionRand(r)); s.selectionRand != r || s.playoutRand == r {
+		t.Fatal("expected the selection random number generator to only break final move ties")
+	}
+}
@@ -0,0 +1,99 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// EvaluatorScratch is an Evaluator with mutable state, like its own random number generator
+// or board buffers, that is private to one worker of a parallel search.
+type EvaluatorScratch interface {
+	Evaluator
+}
+
+// ScratchFactory is an optional interface that an Evaluator can implement so that parallel
+// searches do not require it to be safe for concurrent use. NewScratch is called once per
+// worker and the returned EvaluatorScratch is only used by that worker.
+type ScratchFactory interface {
+	NewScratch() EvaluatorScratch
+}
+
+// SearchParallel searches board with workers independent trees in parallel, each limited by b,
+// and combines the root Move statistics of all trees like SearchDeterminized.
+// If the Evaluator implements ScratchFactory, every worker uses its own EvaluatorScratch,
+// otherwise the Evaluator must be safe for concurrent use. The Expander, the playout policies,
+// the function of SetPathObserver and the function of SetWarnf are shared by the workers and
+// must always be safe for concurrent use. Trajectory sinks, replay logs and the selection random
+// number generator are not used by the workers.
+func (s *MCTS) SearchParallel(board [][]int, side int, workers int, b SearchBudget) (Move, int64) {
+	if workers < 1 {
+		workers = 1
+	}
+	roots := make([]*treeNode, workers)
+	ws := make([]*MCTS, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		w := s.worker(i)
+		if i == 0 {
+			// the warm start priors are counted once in the merged root
+			w.warm = s.warm
+		}
+		ws[i] = w
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			roots[i] = w.search(copyBoard(board), side, b)
+		}(i)
+	}
+	wg.Wait()
+	s.warm = nil
+	s.rollouts, s.terminals = 0, 0
+	for _, w := range ws {
+		s.rollouts += w.rollouts
+		s.terminals += w.terminals
+	}
+	if s.store != nil {
+		s.storeMergedTranspositions(roots)
+	}
+	root := s.mergeRoots(roots)
+	s.root = root
+	if s.recorder != nil {
+		s.recorder.record(root, board, side)
+	}
+	if roots[0].gameOver || len(root.children) == 0 {
+		return nil, root.visits
+	}
+	return s.bestChild(root).move, root.visits
+}
+
+// worker returns a copy of s with the same configuration and its own mutable state
+// for the i-th worker of a parallel search. The playout policies and the observer functions are
+// not copied, so they are called concurrently by all workers.
+func (s *MCTS) worker(i int) *MCTS {
+	w := *s
+	w.root = nil
+	w.reuseTree = false
+	w.ts = nil
+	w.recorder = nil
+	w.replay = nil
+	w.selectionRand = nil
+	w.tieRng = nil
+	w.rolloutWins = nil
+	w.store = nil
+	w.warm = nil
+	if s.arena != nil {
+		w.arena = &boardArena{}
+	}
+	if f, ok := s.ev.(ScratchFactory); ok {
+		w.ev = f.NewScratch()
+	}
+	if s.playoutRand != nil {
+		w.playoutRand = rand.New(rand.NewSource(s.playoutRand.Int63()))
+	}
+	if s.crn {
+		// common random numbers are shared by the root Moves of a worker, not by the workers,
+		// whose trees would otherwise be identical
+		w.crnSeed = rand.New(rand.NewSource(s.crnSeed + int64(i))).Int63()
+	}
+	return &w
+}
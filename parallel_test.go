@@ -0,0 +1,42 @@
+package mcts
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// scratchEval is a tictactoe evaluator whose random number generator is not safe for
+// concurrent use, so every worker gets its own copy.
+type scratchEval struct {
+	*tttEval
+	seed    int64
+	created *int64
+}
+
+func (e *scratchEval) NewScratch() EvaluatorScratch {
+	n := atomic.AddInt64(e.created, 1)
+	return &scratchEval{tttEval: newTTTEval(e.target, e.seed+n), seed: e.seed, created: e.created}
+}
+
+func TestSearchParallel(t *testing.T) {
+	var created int64
+	ev := &scratchEval{tttEval: newTTTEval(3, 1), seed: 1, created: &created}
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	// X wins at (0, 2).
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	m, visits := s.SearchParallel(board, 1, 4, SearchBudget{MaxIters: 300})
+	if created != 4 {
+		t.Fatalf("expected 4 scratch evaluators, got %v", created)
+	}
+	if mov := m.(*tttMove); mov.i != 0 || mov.j != 2 {
+		t.Fatalf("expected the winning move, got (%v, %v)", mov.i, mov.j)
+	}
+	if visits < 4*300 {
+		t.Fatalf("expected the visits of all workers, got %v", visits)
+	}
+}
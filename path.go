@@ -0,0 +1,60 @@
+package mcts
+
+import (
+	"math"
+	"sort"
+)
+
+// SetPathObserver sets a function that is called in every iteration with the length of its
+// selection path, which is the number of nodes that selection descends below the searched
+// node before expanding. A nil function, which is the default, disables it.
+// PathHistogram.Observe can be used to accumulate the lengths.
+func (s *MCTS) SetPathObserver(f func(length int)) {
+	s.pathObserver = f
+}
+
+// PathHistogram accumulates selection path lengths. The zero value is an empty histogram.
+type PathHistogram struct {
+	counts []int64
+	total  int64
+}
+
+// Observe adds a path of the given length to the histogram.
+func (h *PathHistogram) Observe(length int) {
+	for len(h.counts) <= length {
+		h.counts = append(h.counts, 0)
+	}
+	h.counts[length]++
+	h.total++
+}
+
+// Count returns the number of observed paths.
+func (h *PathHistogram) Count() int64 {
+	return h.total
+}
+
+// Counts returns the number of observed paths of every length, indexed by length.
+func (h *PathHistogram) Counts() []int64 {
+	return append([]int64(nil), h.counts...)
+}
+
+// Percentile returns the smallest length that at least p percent of the observed paths do not
+// exceed, where p is between 0 and 100. It returns 0 for an empty histogram.
+func (h *PathHistogram) Percentile(p float64) int {
+	if h.total == 0 {
+		return 0
+	}
+	var cum []int64
+	var sum int64
+	for _, c := range h.counts {
+		sum += c
+		cum = append(cum, sum)
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.total)))
+	if target < 1 {
+		target = 1
+	}
+	return sort.Search(len(cum), func(i int) bool {
+		return cum[i] >= target
+	})
+}
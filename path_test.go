@@ -0,0 +1,41 @@
+package mcts
+
+import "testing"
+
+func TestPathHistogram(t *testing.T) {
+	var h PathHistogram
+	if h.Percentile(50) != 0 || h.Count() != 0 {
+		t.Fatal("expected an empty histogram")
+	}
+	for _, length := range []int{1, 1, 2, 3, 3, 3, 4, 7, 2, 1} {
+		h.Observe(length)
+	}
+	if h.Count() != 10 || h.Counts()[3] != 3 {
+		t.Fatalf("unexpected counts %v", h.Counts())
+	}
+	for p, want := range map[float64]int{0: 1, 25: 1, 35: 2, 50: 2, 80: 3, 90: 4, 100: 7} {
+		if got := h.Percentile(p); got != want {
+			t.Fatalf("expected percentile %v to be %v, got %v", p, want, got)
+		}
+	}
+}
+
+func TestPathObserver(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	var early, late PathHistogram
+	s.SetPathObserver(func(length int) {
+		if early.Count() < 200 {
+			early.Observe(length)
+		} else {
+			late.Observe(length)
+		}
+	})
+	s.SearchIterations(newBoard(5, 5), 1, 5000)
+	if early.Count()+late.Count() != 5000 {
+		t.Fatalf("expected a path per iteration, got %v", early.Count()+late.Count())
+	}
+	if late.Percentile(50) <= early.Percentile(50) || late.Percentile(100) <= early.Percentile(100) {
+		t.Fatalf("expected paths to get longer as the tree grows, got %v and %v", early.Counts(), late.Counts())
+	}
+}
@@ -0,0 +1,93 @@
+package mcts
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// pauser blocks the search loops of an MCTS while it is paused. running is the number of
+// search loops and parked the number of them that are blocked.
+type pauser struct {
+	requested int32
+	mu        sync.Mutex
+	cond      *sync.Cond
+	paused    bool
+	running   int
+	parked    int
+}
+
+func newPauser() *pauser {
+	p := &pauser{}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Pause pauses the search that runs in another goroutine and returns once it is blocked between
+// two iterations, so that the tree can be read, for example with CurrentBest, until Resume is
+// called. The blocked search does not use CPU and keeps its tree and its budget clock, so a time
+// budget keeps running while it is paused. A search that starts while paused blocks before its
+// first iteration. The whole search is paused, including the root pre-expansion and the extra
+// iterations of SetResolveDisagreement and ExtendPV. Pause must only be followed by reads of the
+// tree if a search is running or none will start before Resume.
+func (s *MCTS) Pause() {
+	p := s.pause
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+	atomic.StoreInt32(&p.requested, 1)
+	for p.parked < p.running {
+		p.cond.Wait()
+	}
+}
+
+// Resume resumes a search paused with Pause. It does nothing if the search is not paused.
+func (s *MCTS) Resume() {
+	p := s.pause
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = false
+	atomic.StoreInt32(&p.requested, 0)
+	p.cond.Broadcast()
+}
+
+// enterRun registers a search loop with the pauser.
+func (p *pauser) enterRun() {
+	p.mu.Lock()
+	p.running++
+	p.mu.Unlock()
+}
+
+// exitRun unregisters a search loop, which a pending Pause no longer waits for.
+func (p *pauser) exitRun() {
+	p.mu.Lock()
+	p.running--
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// wait blocks while the search is paused. It is called between iterations and only takes the
+// lock if a pause is requested.
+func (p *pauser) wait() {
+	if atomic.LoadInt32(&p.requested) == 0 {
+		return
+	}
+	p.mu.Lock()
+	p.parked++
+	p.cond.Broadcast()
+	for p.paused {
+		p.cond.Wait()
+	}
+	p.parked--
+	p.mu.Unlock()
+}
+
+// CurrentBest returns the Move that the current tree would choose and the visits of its root,
+// which can be read during a search that is paused with Pause. It returns a nil Move and 0
+// visits if there is no tree or the root has no children.
+func (s *MCTS) CurrentBest() (Move, int64) {
+	root := s.root
+	if root == nil || len(root.children) == 0 {
+		return nil, 0
+	}
+	return s.bestChild(root).move, root.visits
+}
@@ -0,0 +1,99 @@
+package mcts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	started := make(chan struct{})
+	iters := 0
+	s.SetPathObserver(func(int) {
+		iters++
+		if iters == 100 {
+			close(started)
+		}
+	})
+	done := make(chan Move)
+	go func() {
+		m, _ := s.SearchWithBudget(newBoard(4, 4), 1, SearchBudget{Duration: 200 * time.Millisecond})
+		done <- m
+	}()
+	<-started
+	s.Pause()
+	m, visits := s.CurrentBest()
+	if m == nil || visits < 100 {
+		t.Fatalf("expected a best move while paused, got %v with %v visits", m, visits)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, v := s.CurrentBest(); v != visits {
+		t.Fatalf("expected the paused search not to run, got %v visits after %v", v, visits)
+	}
+	s.Resume()
+	if m := <-done; m == nil {
+		t.Fatal("expected the resumed search to finish with a move")
+	}
+	if _, v := s.CurrentBest(); v <= visits {
+		t.Fatalf("expected the resumed search to add visits to %v, got %v", visits, v)
+	}
+}
+
+func TestPauseWithoutSearch(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.Pause()
+	s.Resume()
+	if m, _ := s.Search(newBoard(3, 3), 1, 0, 0, 10); m == nil {
+		t.Fatal("expected a search after resuming to run")
+	}
+	if m, _ := s.CurrentBest(); m == nil {
+		t.Fatal("expected the best move of the finished search")
+	}
+}
+
+func TestPauseDuringDisagreement(t *testing.T) {
+	// find a budget whose search ends with a disagreement like TestResolveDisagreement
+	iters := 0
+	for i := 20; i < 200 && iters == 0; i++ {
+		ev := newTTTEval(4, 1)
+		s := New(ev, ev)
+		s.SearchIterations(newBoard(4, 4), 1, i)
+		if visited, valued := s.disputedChildren(s.root); visited != valued {
+			iters = i
+		}
+	}
+	if iters == 0 {
+		t.Fatal("expected a disagreement for a small budget")
+	}
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.SetResolveDisagreement(1000)
+	started := make(chan struct{})
+	n := 0
+	s.SetPathObserver(func(int) {
+		n++
+		if n == iters+1 {
+			close(started)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		s.SearchIterations(newBoard(4, 4), 1, iters)
+		close(done)
+	}()
+	select {
+	case <-started:
+	case <-done:
+		t.Fatal("expected extra iterations")
+	}
+	s.Pause()
+	_, visits := s.CurrentBest()
+	time.Sleep(10 * time.Millisecond)
+	if _, v := s.CurrentBest(); v != visits {
+		t.Fatalf("expected the paused resolution not to run, got %v visits after %v", v, visits)
+	}
+	s.Resume()
+	<-done
+}
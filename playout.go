@@ -0,0 +1,100 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// PlayoutPolicy chooses the moves of rollouts, for example with weighted or greedy heuristics.
+// PlayoutMove returns nil if there are no moves left, like Evaluator.RandomMove.
+// r is the playout random number generator of the search.
+type PlayoutPolicy interface {
+	PlayoutMove(board [][]int, side int, r *rand.Rand) Move
+}
+
+// ConfidencePolicy is an optional interface that a PlayoutPolicy can implement when the
+// reliability of its rollouts varies. Confidence returns a weight between 0 and 1 of the
+// rollout that ended on board after plies moves. The reward of the rollout is scaled by its
+// weight, and it adds its weight instead of a full visit to the effective visits that the mean
+// values and UCB values of nodes are computed with. Weights do not apply to shared statistics.
+type ConfidencePolicy interface {
+	Confidence(board [][]int, plies int) float64
+}
+
+// SetPlayoutPolicy sets the policy that chooses rollout moves.
+// A nil policy, which is the default, uses Evaluator.RandomMove.
+func (s *MCTS) SetPlayoutPolicy(p PlayoutPolicy) {
+	s.policy = p
+}
+
+// SetPlayoutPolicyBySide sets playout policies for the sides in bySide, which choose the
+// rollout moves of that side, so that for example rollouts can model a specific opponent
+// while the searched side plays randomly. Sides that are not in bySide use the policy set with
+// SetPlayoutPolicy. Only that policy weights rollouts if it implements ConfidencePolicy.
+func (s *MCTS) SetPlayoutPolicyBySide(bySide map[int]PlayoutPolicy) {
+	s.policyBySide = bySide
+}
+
+// SetPlayoutRand sets the random number generator that is passed to the playout policy.
+// Rollouts can stay stochastic with it while selection is deterministic, or the other way
+// around. If it is not set, a generator seeded with the current time is created when needed.
+func (s *MCTS) SetPlayoutRand(r *rand.Rand) {
+	s.playoutRand = r
+}
+
+// SetSelectionRand sets the random number generator used to break ties when choosing the
+// final move by visits, which is the last of the rules listed on Search. If it is nil, which is
+// the default, ties are broken in favor of the child that was expanded first.
+func (s *MCTS) SetSelectionRand(r *rand.Rand) {
+	s.selectionRand = r
+}
+
+// SetCommonRandomNumbers sets whether the playout random number generator is reseeded before
+// every rollout from seed and the visits of the root child that the rollout is under, which
+// reduces the variance of the comparison of root Moves with common random numbers: the k-th
+// rollouts under every root Move use the same random stream, so their values differ by the Moves
+// rather than by the luck of their rollouts. It only applies to rollouts of a playout policy,
+// since Evaluator.RandomMove does not use the generator, and it is disabled by default.
+// Every worker of SearchParallel derives its own seed from seed.
+func (s *MCTS) SetCommonRandomNumbers(common bool, seed int64) {
+	s.crn, s.crnSeed = common, seed
+}
+
+// seedPlayout reseeds the playout random number generator for a rollout from leaf.
+func (s *MCTS) seedPlayout(leaf *treeNode) {
+	n := leaf
+	for n.parent != nil && n.parent.parent != nil {
+		n = n.parent
+	}
+	seed := s.crnSeed + n.visits
+	if s.playoutRand == nil {
+		s.playoutRand = rand.New(rand.NewSource(seed))
+		return
+	}
+	s.playoutRand.Seed(seed)
+}
+
+// playoutMove returns the next rollout move of side.
+func (s *MCTS) playoutMove(board [][]int, side int) Move {
+	p := s.policy
+	if bp, ok := s.policyBySide[side]; ok {
+		p = bp
+	}
+	if p == nil {
+		return s.ev.RandomMove(board, side)
+	}
+	if s.playoutRand == nil {
+		s.playoutRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return p.PlayoutMove(board, side, s.playoutRand)
+}
+
+// confidence returns the weight of the rollout that ended with o.
+func (s *MCTS) confidence(o outcome) float64 {
+	cp, ok := s.policy.(ConfidencePolicy)
+	if !ok {
+		return 1
+	}
+	return math.Max(0, math.Min(1, cp.Confidence(o.board, o.plies)))
+}
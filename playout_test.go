@@ -0,0 +1,245 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// uniformPolicy plays uniformly random tictactoe moves with the playout random number generator.
+type uniformPolicy struct {
+	ev *tttEval
+}
+
+func (p *uniformPolicy) PlayoutMove(board [][]int, side int, r *rand.Rand) Move {
+	moves := p.ev.Expand(board, side)
+	if len(moves) == 0 {
+		return nil
+	}
+	return moves[r.Intn(len(moves))]
+}
+
+func TestPlayoutRand(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	run := func(playoutSeed, selectionSeed int64) [][]Move {
+		s := New(ev, ev)
+		s.SetPlayoutPolicy(&uniformPolicy{ev: ev})
+		s.SetPlayoutRand(rand.New(rand.NewSource(playoutSeed)))
+		s.SetSelectionRand(rand.New(rand.NewSource(selectionSeed)))
+		rs := &recordingSink{}
+		s.SetTrajectorySink(rs)
+		s.Search(newBoard(3, 3), 1, 0, 0, 100)
+		return rs.moves
+	}
+	if !reflect.DeepEqual(run(1, 1), run(1, 1)) {
+		t.Fatal("expected identical rollouts with the same playout seed")
+	}
+	if reflect.DeepEqual(run(1, 1), run(2, 1)) {
+		t.Fatal("expected different rollouts with different playout seeds")
+	}
+	if !reflect.DeepEqual(run(1, 1), run(1, 2)) {
+		t.Fatal("expected the rollouts not to depend on the selection seed")
+	}
+
+	// the tie breaks of the final move do not depend on the playout seed
+	root := &treeNode{board: newBoard(3, 3), side: 2}
+	New(ev, ev).expand(root, 0)
+	choices := func(playoutSeed int64) []*treeNode {
+		s := New(ev, ev)
+		s.SetPlayoutRand(rand.New(rand.NewSource(playoutSeed)))
+		s.SetSelectionRand(rand.New(rand.NewSource(1)))
+		res := make([]*treeNode, 20)
+		for i := range res {
+			res[i] = s.bestChild(root)
+		}
+		return res
+	}
+	if !reflect.DeepEqual(choices(1), choices(2)) {
+		t.Fatal("expected the same tie breaks with different playout seeds")
+	}
+}
+
+func TestSelectionRand(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	// all children are tied
+	root := &treeNode{board: newBoard(3, 3), side: 2}
+	New(ev, ev).expand(root, 0)
+	choices := func(seed int64) []*treeNode {
+		s := New(ev, ev)
+		s.SetSelectionRand(rand.New(rand.NewSource(seed)))
+		res := make([]*treeNode, 20)
+		for i := range res {
+			res[i] = s.bestChild(root)
+		}
+		return res
+	}
+	a, b := choices(1), choices(1)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("expected the same tie breaks with the same selection seed")
+	}
+	first := true
+	for _, ch := range a {
+		first = first && ch == root.children[0]
+	}
+	if first {
+		t.Fatal("expected ties to be broken randomly")
+	}
+	if New(ev, ev).bestChild(root) != root.children[0] {
+		t.Fatal("expected the first child without a selection random number generator")
+	}
+}
+
+// confidentPolicy plays like uniformPolicy with a fixed rollout confidence.
+type confidentPolicy struct {
+	uniformPolicy
+	confidence float64
+}
+
+func (p *confidentPolicy) Confidence(board [][]int, plies int) float64 {
+	return p.confidence
+}
+
+func TestConfidencePolicy(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	shift := func(confidence float64) float64 {
+		s := New(ev, ev)
+		s.SetPlayoutPolicy(&confidentPolicy{uniformPolicy: uniformPolicy{ev: ev}, confidence: confidence})
+		s.rolloutWins = make(map[int]int64)
+		root := &treeNode{side: 2, toMove: 1, visits: 10}
+		child := &treeNode{parent: root, side: 1, depth: 1, visits: 10}
+		root.children = []*treeNode{child}
+		s.backpropagate(child, outcome{winner: 1, board: newBoard(3, 3)})
+		if child.visits != 11 {
+			t.Fatalf("expected a full visit, got %v visits", child.visits)
+		}
+		return child.value()
+	}
+	low, high := shift(0.1), shift(0.9)
+	if low <= 0 || low >= high || high > shift(1) {
+		t.Fatalf("expected low confidence rollouts to move the value less, got %v and %v", low, high)
+	}
+	if math.Abs(shift(1)-1.0/11) > 1e-9 {
+		t.Fatalf("expected a full confidence rollout to count as an unweighted one, got %v", shift(1))
+	}
+}
+
+// greedyPolicy always plays the first empty tictactoe cell.
+type greedyPolicy struct {
+	ev *tttEval
+}
+
+func (p *greedyPolicy) PlayoutMove(board [][]int, side int, r *rand.Rand) Move {
+	moves := p.ev.Expand(board, side)
+	if len(moves) == 0 {
+		return nil
+	}
+	return moves[0]
+}
+
+// countingPolicy counts the rollout moves that a policy chooses for every side.
+type countingPolicy struct {
+	PlayoutPolicy
+	sides map[int]int
+}
+
+func (p *countingPolicy) PlayoutMove(board [][]int, side int, r *rand.Rand) Move {
+	p.sides[side]++
+	return p.PlayoutPolicy.PlayoutMove(board, side, r)
+}
+
+func TestPlayoutPolicyBySide(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	greedy := &countingPolicy{PlayoutPolicy: &greedyPolicy{ev: ev}, sides: make(map[int]int)}
+	random := &countingPolicy{PlayoutPolicy: &uniformPolicy{ev: ev}, sides: make(map[int]int)}
+	s := New(ev, ev)
+	s.SetPlayoutPolicy(random)
+	s.SetPlayoutPolicyBySide(map[int]PlayoutPolicy{2: greedy})
+	s.SearchIterations(newBoard(4, 4), 1, 200)
+	if greedy.sides[2] == 0 || greedy.sides[1] != 0 {
+		t.Fatalf("expected the greedy policy to play only for side 2, got %v", greedy.sides)
+	}
+	if random.sides[1] == 0 || random.sides[2] != 0 {
+		t.Fatalf("expected the default policy to play only for side 1, got %v", random.sides)
+	}
+}
+
+// coinMove is a root move of coinEval, which wins its rollouts with probability p, or the
+// random draw of a rollout.
+type coinMove struct {
+	p, u float64
+}
+
+func (m *coinMove) Eval() float64 { return 0 }
+
+// coinEval is a game of a single move of side 1 followed by a random draw, whose rollouts
+// depend mostly on luck. The board holds the winning probability of the played move in percent.
+type coinEval struct{}
+
+func (e *coinEval) RandomMove(board [][]int, side int) Move { return nil }
+
+func (e *coinEval) ApplyMove(board [][]int, side int, m Move) (bool, int, error) {
+	cm := m.(*coinMove)
+	if board[0][0] == 0 {
+		board[0][0] = int(cm.p * 100)
+		return false, 0, nil
+	}
+	if cm.u*100 < float64(board[0][0]) {
+		return true, 1, nil
+	}
+	return true, 2, nil
+}
+
+func (e *coinEval) NextPlayer(side int) int { return 3 - side }
+func (e *coinEval) PrevPlayer(side int) int { return 3 - side }
+
+func (e *coinEval) Expand(board [][]int, side int) []Move {
+	if board[0][0] != 0 {
+		return nil
+	}
+	return []Move{&coinMove{p: 0.55}, &coinMove{p: 0.45}}
+}
+
+func (e *coinEval) PlayoutMove(board [][]int, side int, r *rand.Rand) Move {
+	return &coinMove{u: r.Float64()}
+}
+
+func TestCommonRandomNumbers(t *testing.T) {
+	ev := &coinEval{}
+	// variance returns the variance of the visit share of the better move over searches
+	variance := func(common bool) float64 {
+		var sum, sq float64
+		const runs = 50
+		for i := int64(0); i < runs; i++ {
+			s := New(ev, ev)
+			s.SetPlayoutPolicy(ev)
+			s.SetPlayoutRand(rand.New(rand.NewSource(i)))
+			s.SetCommonRandomNumbers(common, i)
+			s.Search([][]int{{0}}, 1, 0, 0, 200)
+			share := float64(s.root.children[0].visits) / float64(s.root.visits)
+			sum += share
+			sq += share * share
+		}
+		mean := sum / runs
+		return sq/runs - mean*mean
+	}
+	if plain, common := variance(false), variance(true); common >= plain/2 {
+		t.Fatalf("expected common random numbers to reduce the variance %v, got %v", plain, common)
+	}
+}
+
+func TestCommonRandomNumbersWorkers(t *testing.T) {
+	ev := &coinEval{}
+	s := New(ev, ev)
+	s.SetPlayoutPolicy(ev)
+	s.SetCommonRandomNumbers(true, 1)
+	r0 := s.worker(0).search([][]int{{0}}, 1, SearchBudget{MaxIters: 100})
+	r1 := s.worker(1).search([][]int{{0}}, 1, SearchBudget{MaxIters: 100})
+	same := true
+	for i, ch := range r0.children {
+		same = same && ch.visits == r1.children[i].visits && ch.winScore == r1.children[i].winScore
+	}
+	if same {
+		t.Fatal("expected the workers to use different random streams")
+	}
+}
@@ -0,0 +1,55 @@
+package mcts
+
+import "math"
+
+// Ranker reports whether the Move of a ranks before the Move of b as the final Move.
+type Ranker func(a, b ChildStat) bool
+
+// SetFinalRanker sets the ordering that chooses the final Move among the children of a node,
+// which replaces every other choice of the final Move listed on Search apart from proven Moves.
+// The first child in the ordering is chosen, and among children that rank equally the one that
+// was expanded first. A proven Move is still chosen first unless the search keeps searching
+// after a proof. The default nil ranker keeps the other settings.
+func (s *MCTS) SetFinalRanker(r Ranker) {
+	s.finalRanker = r
+}
+
+// rankedChild returns the child of n that ranks first with the final ranker.
+func (s *MCTS) rankedChild(n *treeNode) *treeNode {
+	res := n.children[0]
+	best := childStat(res)
+	for _, ch := range n.children[1:] {
+		if st := childStat(ch); s.finalRanker(st, best) {
+			res, best = ch, st
+		}
+	}
+	return res
+}
+
+// ByVisits ranks the most visited Move first and breaks ties by value, which is the default
+// final Move choice.
+func ByVisits(a, b ChildStat) bool {
+	if a.Visits != b.Visits {
+		return a.Visits > b.Visits
+	}
+	return a.Value > b.Value
+}
+
+// ByValue ranks the Move with the highest value first and breaks ties by visits.
+func ByValue(a, b ChildStat) bool {
+	if a.Value != b.Value {
+		return a.Value > b.Value
+	}
+	return a.Visits > b.Visits
+}
+
+// ByLowerBound returns a risk adjusted Ranker that ranks Moves by the lower bound
+// Value - c/sqrt(Visits), which penalizes the values of rarely visited Moves.
+func ByLowerBound(c float64) Ranker {
+	bound := func(st ChildStat) float64 {
+		return st.Value - c/math.Sqrt(math.Max(1, float64(st.Visits)))
+	}
+	return func(a, b ChildStat) bool {
+		return bound(a) > bound(b)
+	}
+}
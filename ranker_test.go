@@ -0,0 +1,43 @@
+package mcts
+
+import "testing"
+
+func TestFinalRanker(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	calls := 0
+	// rank by value, then by visits
+	s.SetFinalRanker(func(a, b ChildStat) bool {
+		calls++
+		return ByValue(a, b)
+	})
+	m, _ := s.SearchIterations(newBoard(4, 4), 1, 500)
+	if calls == 0 {
+		t.Fatal("expected the ranker to choose the final move")
+	}
+	var best *treeNode
+	for _, ch := range s.root.children {
+		if best == nil || ByValue(childStat(ch), childStat(best)) {
+			best = ch
+		}
+	}
+	if m != best.move {
+		t.Fatalf("expected the highest value move %+v, got %+v", best.move, m)
+	}
+
+	s.SetFinalRanker(ByVisits)
+	if got, want := s.bestChild(s.root), bestChild(s.root); got != want {
+		t.Fatalf("expected ByVisits to match the default choice, got %+v", got.move)
+	}
+}
+
+func TestByLowerBound(t *testing.T) {
+	rare := ChildStat{Visits: 1, Value: 0.9}
+	frequent := ChildStat{Visits: 400, Value: 0.5}
+	if ByValue(frequent, rare) {
+		t.Fatal("expected ByValue to prefer the higher value")
+	}
+	if r := ByLowerBound(1); !r(frequent, rare) || r(rare, frequent) {
+		t.Fatal("expected the lower bound to penalize the rarely visited move")
+	}
+}
@@ -0,0 +1,69 @@
+package mcts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReplayEntry records the decisions of a single search iteration.
+// Path holds the child indices from the root to the selected node, Expanded the Moves that
+// were added to the selected node, Rollout the rollout Moves and Winner the outcome.
+type ReplayEntry struct {
+	Path     []int
+	Expanded []Move
+	Rollout  []Move
+	Winner   int
+}
+
+// String formats the entry on a single line, so that logs of two runs can be diffed.
+func (e ReplayEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "path=%v expanded=[", e.Path)
+	writeMoves(&b, e.Expanded)
+	b.WriteString("] rollout=[")
+	writeMoves(&b, e.Rollout)
+	fmt.Fprintf(&b, "] winner=%v", e.Winner)
+	return b.String()
+}
+
+func writeMoves(b *strings.Builder, moves []Move) {
+	for i, m := range moves {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(b, "%v", m)
+	}
+}
+
+// EnableReplayLog starts recording a ReplayEntry for every search iteration,
+// discarding any previously recorded entries.
+func (s *MCTS) EnableReplayLog() {
+	s.replay = make([]ReplayEntry, 0)
+}
+
+// ReplayLog returns the entries recorded since EnableReplayLog was called.
+func (s *MCTS) ReplayLog() []ReplayEntry {
+	return s.replay
+}
+
+// childPath returns the child indices leading from the root to n.
+func childPath(n *treeNode) []int {
+	res := make([]int, n.depth)
+	for ; n.parent != nil; n = n.parent {
+		for i, ch := range n.parent.children {
+			if ch == n {
+				res[n.depth-1] = i
+				break
+			}
+		}
+	}
+	return res
+}
+
+func childMoves(children []*treeNode) []Move {
+	res := make([]Move, len(children))
+	for i, ch := range children {
+		res[i] = ch.move
+	}
+	return res
+}
@@ -0,0 +1,34 @@
+package mcts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplayLog(t *testing.T) {
+	run := func(seed int64) string {
+		ev := newTTTEval(3, seed)
+		s := New(ev, ev)
+		s.EnableReplayLog()
+		s.Search(newBoard(3, 3), 1, 0, 0, 100)
+		log := s.ReplayLog()
+		if len(log) != 100 {
+			t.Fatalf("expected 100 entries, got %v", len(log))
+		}
+		lines := make([]string, len(log))
+		for i, e := range log {
+			lines[i] = e.String()
+		}
+		return strings.Join(lines, "\n")
+	}
+	a, b := run(1), run(1)
+	if a != b {
+		t.Fatal("expected identical logs with the same seed")
+	}
+	if a == run(2) {
+		t.Fatal("expected different logs with different seeds")
+	}
+	if !strings.HasPrefix(a, "path=[] expanded=[&{0 0 1 0} ") {
+		t.Fatalf("expected the first iteration to expand the root, got %v", strings.SplitN(a, "\n", 2)[0])
+	}
+}
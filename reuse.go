@@ -0,0 +1,133 @@
+package mcts
+
+// SetTreeReuse sets whether Search reuses the tree of the previous search.
+// When enabled, Search continues from the previous root or from one of its children or
+// grandchildren if its board and side to move match the searched ones, for example after
+// the searched side and its opponent have each played a move. The rest of the tree is discarded.
+func (s *MCTS) SetTreeReuse(reuse bool) {
+	s.reuseTree = reuse
+}
+
+// RootBoard returns a copy of the board of the retained root, or nil if there is no root or
+// the root combines several trees, like after SearchParallel.
+func (s *MCTS) RootBoard() [][]int {
+	if s.root == nil || s.root.board == nil {
+		return nil
+	}
+	return copyBoard(s.root.board)
+}
+
+// SetRoot discards the tree and retains a new root for side to move on board, which is
+// copied. With tree reuse, a search of the same board and side continues from it, so that
+// the search can follow an external game state without searching every position.
+func (s *MCTS) SetRoot(board [][]int, side int) {
+	s.root = s.newRoot(board, side)
+	s.memUsed = treeFootprint(s.root)
+}
+
+// reusableRoot returns the node of the retained tree that matches board and side, detached
+// from its parent, or nil if there is none.
+func (s *MCTS) reusableRoot(board [][]int, side int) *treeNode {
+	if !s.reuseTree || s.root == nil {
+		return nil
+	}
+	matches := func(n *treeNode) bool {
+		return s.toMove(n) == side && boardsEqual(s.boardOf(n), board)
+	}
+	if matches(s.root) {
+		return s.root
+	}
+	for _, ch := range s.root.children {
+		if matches(ch) {
+			s.rebase(ch)
+			return ch
+		}
+		for _, gch := range ch.children {
+			if matches(gch) {
+				s.rebase(gch)
+				return gch
+			}
+		}
+	}
+	return nil
+}
+
+// rebase detaches n from its parent and makes it a root at depth 0.
+func (s *MCTS) rebase(n *treeNode) {
+	// a root always keeps its board, which compact descendants are rebuilt from
+	n.board = s.boardOf(n)
+	n.toMove = s.toMove(n)
+	n.parent = nil
+	shift := n.depth
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		n.depth -= shift
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(n)
+}
+
+func boardsEqual(a, b [][]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// warmStartVisits is the number of visits that the value of a warm started child counts for.
+const warmStartVisits = 10
+
+// warmPrior is the mean value of a root Move of a previous search.
+type warmPrior struct {
+	move  Move
+	value float64
+}
+
+// WarmStart initializes the next search with the root statistics of the last search of prev,
+// for example after an undo in an analysis. Root children whose Move equals a root Move of prev
+// start with its mean value, counted as warmStartVisits visits, which blends the history into
+// the fresh search. It only applies to the next search, and only if that search builds a new
+// tree. SearchParallel and SearchDeterminized count the priors once, in a single worker tree or
+// sample. It does nothing if prev has not searched yet.
+func (s *MCTS) WarmStart(prev *MCTS) {
+	s.warm = nil
+	if prev.root == nil {
+		return
+	}
+	for _, ch := range prev.root.children {
+		if ch.visits > 0 {
+			s.warm = append(s.warm, warmPrior{move: ch.move, value: ch.value()})
+		}
+	}
+}
+
+// warmStart adds the warm start visits of the root child n, if its Move has a prior.
+func (s *MCTS) warmStart(n *treeNode) {
+	for _, p := range s.warm {
+		if !s.moveEqual(p.move, n.move) {
+			continue
+		}
+		for ch := n; ch != nil; ch = ch.parent {
+			v := p.value
+			if ch.side != n.side {
+				v = -v
+			}
+			ch.visits += warmStartVisits
+			ch.winScore += v * warmStartVisits
+			ch.sqScore += v * v * warmStartVisits
+		}
+		return
+	}
+}
@@ -0,0 +1,169 @@
+package mcts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTreeReuse(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	board := newBoard(3, 3)
+	s.Search(copyBoard(board), 1, 0, 0, 200)
+	first := s.root
+	_, visits := s.Search(copyBoard(board), 1, 0, 0, 100)
+	if s.root != first || visits <= 200 {
+		t.Fatalf("expected the tree to be reused, got %v root visits", visits)
+	}
+
+	// X and O have each played a move that is in the tree.
+	x := s.bestChild(first)
+	o := x.children[0]
+	next := copyBoard(o.board)
+	s.Search(next, 1, 0, 0, 10)
+	if s.root != o {
+		t.Fatal("expected the grandchild to become the root")
+	}
+	if o.parent != nil || o.depth != 0 {
+		t.Fatal("expected the new root to be detached at depth 0")
+	}
+	for _, ch := range o.children {
+		if ch.depth != 1 {
+			t.Fatalf("expected children of the new root at depth 1, got %v", ch.depth)
+		}
+	}
+}
+
+func TestStrictDeadline(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	s.SetStrictDeadline(true)
+	board := newBoard(3, 3)
+	m, visits := s.Search(copyBoard(board), 1, 0, 0, 200)
+	rs := &recordingSink{}
+	s.SetTrajectorySink(rs)
+	m2, visits2 := s.Search(copyBoard(board), 1, 0, 0, 0)
+	if len(rs.moves) != 0 {
+		t.Fatalf("expected no iterations, got %v", len(rs.moves))
+	}
+	if m2 != m || visits2 != visits {
+		t.Fatal("expected the best move of the reused tree")
+	}
+
+	fresh := New(ev, ev)
+	fresh.SetStrictDeadline(true)
+	fresh.SetTrajectorySink(rs)
+	if m, _ := fresh.Search(copyBoard(board), 1, 0, 0, 0); m == nil {
+		t.Fatal("expected a random fallback move")
+	}
+	if len(rs.moves) != 0 {
+		t.Fatalf("expected no iterations, got %v", len(rs.moves))
+	}
+}
+
+func TestSetRoot(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if s.RootBoard() != nil {
+		t.Fatal("expected no root board before a search")
+	}
+	board := [][]int{
+		{1, 0, 0},
+		{0, 2, 0},
+		{0, 0, 0},
+	}
+	s.SetRoot(board, 1)
+	board[2][2] = 1
+	got := s.RootBoard()
+	if got[2][2] != 0 || got[0][0] != 1 || got[1][1] != 2 {
+		t.Fatalf("expected the root to keep its own copy of the board, got %v", got)
+	}
+	got[0][1] = 2
+	if s.RootBoard()[0][1] != 0 {
+		t.Fatal("expected RootBoard to return a copy")
+	}
+	if s.RootNode().Visits() != 0 || len(s.RootNode().Children()) != 0 {
+		t.Fatal("expected a new tree")
+	}
+
+	s.SetTreeReuse(true)
+	root := s.root
+	board[2][2] = 0
+	s.Search(board, 1, 0, 0, 50)
+	if s.root != root {
+		t.Fatal("expected the search to continue from the set root")
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	// X wins with (0, 2)
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	prev := New(ev, ev)
+	prev.SetKeepSearchingAfterProof(true)
+	prev.SearchIterations(copyBoard(board), 1, 500)
+
+	s := New(ev, ev)
+	s.WarmStart(prev)
+	s.SearchIterations(copyBoard(board), 1, 1)
+	for i, ch := range s.root.children {
+		if mov := ch.move.(*tttMove); mov.i == 0 && mov.j == 2 {
+			if ch.value() < 0.5 || ch.visits <= warmStartVisits {
+				t.Fatalf("expected the winning move to start with the previous value, got %v", ch.value())
+			}
+		}
+		// only the first child has a rollout besides its expansion visit
+		if want := prev.root.children[i].value() * warmStartVisits; i > 0 && math.Abs(ch.winScore-want) > 1e-9 {
+			t.Fatalf("expected the previous value of child %v, got a score of %v instead of %v", i, ch.winScore, want)
+		}
+	}
+
+	s.SearchIterations(copyBoard(board), 1, 1)
+	for _, ch := range s.root.children {
+		if ch.visits > 2 {
+			t.Fatal("expected the warm start to apply to a single search")
+		}
+	}
+}
+
+func TestWarmStartMerged(t *testing.T) {
+	var created int64
+	ev := &scratchEval{tttEval: newTTTEval(3, 1), seed: 1, created: &created}
+	// X wins with (0, 2)
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	prev := New(ev, ev)
+	prev.SetKeepSearchingAfterProof(true)
+	prev.SearchIterations(copyBoard(board), 1, 500)
+
+	check := func(name string, root *treeNode) {
+		for _, ch := range root.children {
+			if mov := ch.move.(*tttMove); mov.i == 0 && mov.j == 2 && (ch.visits <= warmStartVisits || ch.visits >= 2*warmStartVisits) {
+				t.Fatalf("expected the %v search to count the warm start once, got %v visits", name, ch.visits)
+			}
+		}
+	}
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	s.WarmStart(prev)
+	s.SearchParallel(copyBoard(board), 1, 4, SearchBudget{MaxIters: 1})
+	check("parallel", s.root)
+	if s.warm != nil {
+		t.Fatal("expected the warm start to apply to a single parallel search")
+	}
+	s.WarmStart(prev)
+	s.SearchDeterminized(func() [][]int { return copyBoard(board) }, 4, 1, 0, 0, 1)
+	check("determinized", s.root)
+	if s.warm != nil {
+		t.Fatal("expected the warm start to apply to a single determinized search")
+	}
+}
@@ -0,0 +1,35 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDiscount(t *testing.T) {
+	// every game is won by player 1 after 2 plies
+	g := &marginGame{r: rand.New(rand.NewSource(1))}
+	s := New(g, g)
+	s.SetDiscount(0.5)
+	if v, _ := s.Evaluate(newBoard(1, 2), 1, SearchBudget{MaxIters: 50}); v != 0.25 {
+		t.Fatalf("expected a value discounted over 2 plies, got %v", v)
+	}
+	s.SetDiscount(0)
+	if v, _ := s.Evaluate(newBoard(1, 2), 1, SearchBudget{MaxIters: 50}); v != 1 {
+		t.Fatalf("expected an undiscounted value of 1, got %v", v)
+	}
+}
+
+func TestDrawReward(t *testing.T) {
+	// the only move left draws
+	board := [][]int{
+		{1, 2, 1},
+		{1, 2, 2},
+		{2, 1, 0},
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetDrawReward(-0.3)
+	if v, _ := s.Evaluate(board, 1, SearchBudget{MaxIters: 10}); v != -0.3 {
+		t.Fatalf("expected the draw reward, got %v", v)
+	}
+}
@@ -0,0 +1,43 @@
+package mcts
+
+import "math"
+
+// SetRiskAversion sets the weight k of the standard deviation of rewards in the choice of the
+// final Move, which then plays the child with the highest mean value minus k times the standard
+// deviation of its rewards. This prefers moves with reliable outcomes over moves with a higher
+// mean and more variance. Children with fewer visits than set with SetMinTrustVisits are ignored
+// unless no child has enough visits. It does not apply in Minimax backup mode or when a rule
+// that comes first on Search chooses the final Move.
+// A k less than or equal to 0, which is the default, chooses by visits.
+func (s *MCTS) SetRiskAversion(k float64) {
+	s.riskAversion = k
+}
+
+// stdDev returns the standard deviation of the rewards of n.
+func (n *treeNode) stdDev() float64 {
+	if n.visits == 0 {
+		return 0
+	}
+	mean := n.value()
+	return math.Sqrt(math.Max(0, n.sqScore/n.weight()-mean*mean))
+}
+
+// riskAdjustedChild returns the child of n with the highest risk adjusted value.
+func (s *MCTS) riskAdjustedChild(n *treeNode) *treeNode {
+	var res *treeNode
+	var best float64
+	for _, minVisits := range []int64{s.minTrustVisits, 0} {
+		for _, ch := range n.children {
+			if ch.visits < minVisits {
+				continue
+			}
+			if v := ch.value() - s.riskAversion*ch.stdDev(); res == nil || v > best {
+				res, best = ch, v
+			}
+		}
+		if res != nil {
+			return res
+		}
+	}
+	panic("could not find any children")
+}
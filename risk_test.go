@@ -0,0 +1,43 @@
+package mcts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRiskAversion(t *testing.T) {
+	root := &treeNode{side: 2}
+	// a mean of 0.3 from wins and losses and a steady mean of 0.2
+	risky := &treeNode{parent: root, side: 1, visits: 100, winScore: 30, sqScore: 100}
+	steady := &treeNode{parent: root, side: 1, visits: 50, winScore: 10, sqScore: 2}
+	root.children = []*treeNode{risky, steady}
+	if math.Abs(risky.stdDev()-math.Sqrt(0.91)) > 1e-9 || steady.stdDev() > 1e-9 {
+		t.Fatalf("unexpected standard deviations %v and %v", risky.stdDev(), steady.stdDev())
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if s.bestChild(root) != risky {
+		t.Fatal("expected the most visited move without risk aversion")
+	}
+	s.SetRiskAversion(0.01)
+	if s.bestChild(root) != risky {
+		t.Fatal("expected the higher mean with a low risk aversion")
+	}
+	s.SetRiskAversion(1)
+	if s.bestChild(root) != steady {
+		t.Fatal("expected the steady move with a high risk aversion")
+	}
+}
+
+func TestRewardVariance(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	s.SearchIterations(newBoard(3, 3), 1, 300)
+	for _, ch := range s.root.children {
+		// rewards are -1, 0 or 1, so the squares count the decided rollouts
+		if ch.sqScore < math.Abs(ch.winScore) || ch.sqScore > float64(ch.visits) {
+			t.Fatalf("expected squared rewards between %v and %v, got %v", math.Abs(ch.winScore), ch.visits, ch.sqScore)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package mcts
+
+// defaultMaxRolloutMoves is the default number of rollout moves after which a rollout is
+// considered to be runaway.
+const defaultMaxRolloutMoves = 10000
+
+// SetMaxRolloutMoves sets the number of moves after which a rollout is aborted, which guards
+// against games without a natural end whose RandomMove never returns nil. An aborted rollout is
+// scored as a draw, or with the Scorer if a score mapper is set. The default is 10000 and a
+// value less than or equal to 0 disables the guard.
+func (s *MCTS) SetMaxRolloutMoves(moves int) {
+	s.maxRolloutMoves = moves
+}
+
+// SetWarnf sets a function that is called with a warning the first time a rollout is aborted
+// by the guard set with SetMaxRolloutMoves, for example log.Printf. A nil function, which is
+// the default, does not warn.
+func (s *MCTS) SetWarnf(f func(format string, args ...interface{})) {
+	s.warnf = f
+}
+
+// warnRunaway warns about an aborted rollout if it is the first one.
+func (s *MCTS) warnRunaway() {
+	if s.warnf == nil || s.warnedRunaway {
+		return
+	}
+	s.warnedRunaway = true
+	s.warnf("mcts: aborted a rollout after %v moves, the game may not terminate", s.maxRolloutMoves)
+}
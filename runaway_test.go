@@ -0,0 +1,51 @@
+package mcts
+
+import "testing"
+
+// endlessGame is a game whose players move a counter forever.
+type endlessGame struct{}
+
+func (g *endlessGame) Expand(board [][]int, side int) []Move {
+	return []Move{&marginMove{cell: 0, val: 1}}
+}
+
+func (g *endlessGame) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return &marginMove{cell: 0, val: 1}
+}
+
+func (g *endlessGame) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	board[0][0]++
+	return false, 0, nil
+}
+
+func (g *endlessGame) NextPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (g *endlessGame) PrevPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func TestMaxRolloutMoves(t *testing.T) {
+	g := &endlessGame{}
+	s := New(g, g)
+	warnings := 0
+	s.SetWarnf(func(format string, args ...interface{}) {
+		warnings++
+	})
+	s.SetMaxRolloutMoves(100)
+	s.EnableReplayLog()
+	s.SearchIterations([][]int{{0}}, 1, 20)
+	for _, e := range s.ReplayLog() {
+		if len(e.Rollout) != 100 || e.Winner != 0 {
+			t.Fatalf("expected rollouts aborted as draws after 100 moves, got %v moves with winner %v", len(e.Rollout), e.Winner)
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected a single warning, got %v", warnings)
+	}
+	// the default guard also stops the search
+	if m, _ := New(g, g).SearchIterations([][]int{{0}}, 1, 5); m == nil {
+		t.Fatal("expected a move")
+	}
+}
@@ -0,0 +1,76 @@
+package mcts
+
+// Scorer is an optional interface that an Evaluator can implement for games that are
+// scored by margin. Score returns the final score of a finished game from the perspective
+// of side, where positive values are good for side.
+type Scorer interface {
+	Score(board [][]int, side int) float64
+}
+
+// SetScoreMapper sets a function that maps a final score reported by a Scorer to a reward
+// between -1.0 and 1.0. When set and the Evaluator implements Scorer, rollout rewards are
+// computed from the mapped score instead of the binary winner, which lets the search prefer
+// larger margins. A nil mapper, which is the default, disables scoring.
+func (s *MCTS) SetScoreMapper(f func(score float64) float64) {
+	s.scoreMapper = f
+}
+
+// SetHandicap sets a value that is added to the final score of side before it is mapped to a
+// reward, like komi in Go or a handicap for a weaker player. Since scores are margins, the
+// value is subtracted from the scores of the other sides. A handicap only applies when the
+// Evaluator implements Scorer and a score mapper is set.
+func (s *MCTS) SetHandicap(side int, value float64) {
+	if s.handicap == nil {
+		s.handicap = make(map[int]float64)
+	}
+	s.handicap[side] = value
+}
+
+// handicapFor returns the total handicap adjustment of the score of side.
+func (s *MCTS) handicapFor(side int) float64 {
+	res := 0.0
+	for h, v := range s.handicap {
+		if h == side {
+			res += v
+		} else {
+			res -= v
+		}
+	}
+	return res
+}
+
+// ReachedTerminal reports whether a rollout of the last search ended the game. If none did, for
+// example because MaxDepth and SetMaxRolloutMoves cut every rollout short, the values of the
+// search are all draws and carry no signal, and SearchResult reports low confidence. The final
+// root Move is then chosen by the Score of the board after each root Move if the Evaluator
+// implements Scorer without a score mapper, which already scores cut rollouts, except for the
+// merged roots of SearchDeterminized and SearchParallel. Their rollouts are summed over all
+// trees. It returns true if no rollout has been run.
+func (s *MCTS) ReachedTerminal() bool {
+	return s.rollouts == 0 || s.terminals > 0
+}
+
+// scoreFallback reports whether the final Move of n is chosen by Score because n is a root and
+// no rollout ended the game. Merged roots have no board to score.
+func (s *MCTS) scoreFallback(n *treeNode) bool {
+	if n.parent != nil || merged(n) {
+		return false
+	}
+	if _, ok := s.ev.(Scorer); !ok || s.scoreMapper != nil {
+		return false
+	}
+	return !s.ReachedTerminal()
+}
+
+// scoredChild returns the child of n whose board has the highest Score for the side that plays it.
+func (s *MCTS) scoredChild(n *treeNode) *treeNode {
+	sc := s.ev.(Scorer)
+	var res *treeNode
+	var best float64
+	for _, ch := range n.children {
+		if score := sc.Score(s.boardOf(ch), ch.side); res == nil || score > best {
+			res, best = ch, score
+		}
+	}
+	return res
+}
@@ -0,0 +1,211 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// marginMove sets cell of a single row board to val.
+type marginMove struct {
+	cell, val int
+}
+
+func (m *marginMove) Eval() float64 {
+	return 0
+}
+
+// marginGame is a two ply game that player 1 always wins. Player 1 picks a margin of 2 or 5
+// and player 2 reduces it by 0 or 1.
+type marginGame struct {
+	r *rand.Rand
+}
+
+func (g *marginGame) Expand(board [][]int, side int) []Move {
+	if board[0][0] == 0 {
+		return []Move{&marginMove{cell: 0, val: 2}, &marginMove{cell: 0, val: 5}}
+	}
+	if board[0][1] == 0 {
+		return []Move{&marginMove{cell: 1, val: 1}, &marginMove{cell: 1, val: 2}}
+	}
+	return nil
+}
+
+func (g *marginGame) RandomMove(board [][]int, currentPlayerSide int) Move {
+	moves := g.Expand(board, currentPlayerSide)
+	if len(moves) == 0 {
+		return nil
+	}
+	return moves[g.r.Intn(len(moves))]
+}
+
+func (g *marginGame) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	mov := m.(*marginMove)
+	board[0][mov.cell] = mov.val
+	if board[0][1] != 0 {
+		return true, 1, nil
+	}
+	return false, 0, nil
+}
+
+func (g *marginGame) NextPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (g *marginGame) PrevPlayer(currentPlayerSide int) int {
+	return 3 - currentPlayerSide
+}
+
+func (g *marginGame) Score(board [][]int, side int) float64 {
+	margin := float64(board[0][0] - (board[0][1] - 1))
+	if side == 1 {
+		return margin
+	}
+	return -margin
+}
+
+func TestScoreMapper(t *testing.T) {
+	g := &marginGame{r: rand.New(rand.NewSource(1))}
+	s := New(g, g)
+	s.SetKeepSearchingAfterProof(true)
+	s.Search(newBoard(1, 2), 1, time.Hour, 0, 200)
+	if top := s.TopMoves(2); math.Abs(top[0].Value-top[1].Value) > 0.05 {
+		t.Fatalf("expected moves with similar values without a score mapper: %v", top)
+	}
+
+	s = New(g, g)
+	s.SetKeepSearchingAfterProof(true)
+	s.SetScoreMapper(func(score float64) float64 {
+		return score / 5
+	})
+	m, _ := s.Search(newBoard(1, 2), 1, time.Hour, 0, 200)
+	if v := m.(*marginMove).val; v != 5 {
+		t.Fatalf("expected the higher margin move, got margin %v", v)
+	}
+	top := s.TopMoves(2)
+	if top[0].Value <= top[1].Value {
+		t.Fatalf("expected the higher margin move to have a higher value: %v", top)
+	}
+}
+
+// scoredTTTEval scores tictactoe games 1 for a win, -1 for a loss and 0 for a draw.
+type scoredTTTEval struct {
+	*tttEval
+}
+
+func (e *scoredTTTEval) Score(board [][]int, side int) float64 {
+	_, winner := e.finished(board)
+	switch winner {
+	case 0:
+		return 0
+	case side:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func TestHandicap(t *testing.T) {
+	value := func(handicap map[int]float64) float64 {
+		// random games of five in a row on a 5x5 board are mostly drawn and nearly fair
+		ev := &scoredTTTEval{tttEval: newTTTEval(5, 1)}
+		s := New(ev, ev)
+		s.SetScoreMapper(func(score float64) float64 {
+			return math.Max(-1, math.Min(1, score))
+		})
+		for side, v := range handicap {
+			s.SetHandicap(side, v)
+		}
+		v, _ := s.Evaluate(newBoard(5, 5), 1, SearchBudget{MaxIters: 1000})
+		return v
+	}
+	if v := value(nil); math.Abs(v) > 0.15 {
+		t.Fatalf("expected a balanced position, got %v", v)
+	}
+	if v := value(map[int]float64{2: 0.5}); v > -0.3 {
+		t.Fatalf("expected a handicap for player 2 to favor player 2, got %v", v)
+	}
+	if v := value(map[int]float64{1: 0.5}); v < 0.3 {
+		t.Fatalf("expected a handicap for player 1 to favor player 1, got %v", v)
+	}
+}
+
+// centerEval is a tictactoe evaluator that scores boards by how central the stones of a side are.
+type centerEval struct {
+	*tttEval
+}
+
+func (e *centerEval) NewScratch() EvaluatorScratch {
+	return &centerEval{tttEval: newTTTEval(e.target, e.r.Int63())}
+}
+
+func (e *centerEval) Score(board [][]int, side int) float64 {
+	res := 0.0
+	c := len(board) / 2
+	for i, row := range board {
+		for j, v := range row {
+			if v == 0 {
+				continue
+			}
+			centrality := float64(len(board) - abs(i-c) - abs(j-c))
+			if v == side {
+				res += centrality
+			} else {
+				res -= centrality
+			}
+		}
+	}
+	return res
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestNoTerminalReached(t *testing.T) {
+	ev := &centerEval{tttEval: newTTTEval(5, 1)}
+	s := New(ev, ev)
+	s.SetMaxRolloutMoves(1)
+	res := s.SearchWithStats(newBoard(5, 5), 1, 0, 1, 200)
+	if !res.LowConfidence || s.ReachedTerminal() {
+		t.Fatal("expected capped rollouts to be reported as low confidence")
+	}
+	if m := res.Move.(*tttMove); m.i != 2 || m.j != 2 {
+		t.Fatalf("expected the move chosen by the score, got %+v", m)
+	}
+
+	s.SetMaxRolloutMoves(0)
+	if res := s.SearchWithStats(newBoard(5, 5), 1, 0, 1, 200); res.LowConfidence || !s.ReachedTerminal() {
+		t.Fatal("expected full rollouts to reach terminals")
+	}
+}
+
+func TestNoTerminalReachedMerged(t *testing.T) {
+	ev := &centerEval{tttEval: newTTTEval(5, 1)}
+	s := New(ev, ev)
+	s.SetMaxRolloutMoves(1)
+	sampler := func() [][]int { return newBoard(5, 5) }
+	if m, _ := s.SearchDeterminized(sampler, 3, 1, 0, 1, 50); m == nil || s.ReachedTerminal() {
+		t.Fatal("expected a move of the merged root without a terminal")
+	}
+	if s.rollouts != 150 {
+		t.Fatalf("expected the rollouts of every determinization, got %v", s.rollouts)
+	}
+	if m, _ := s.SearchParallel(newBoard(5, 5), 1, 2, SearchBudget{MaxDepth: 1, MaxIters: 50}); m == nil || s.ReachedTerminal() {
+		t.Fatal("expected a move of the parallel root without a terminal")
+	}
+	if s.rollouts != 100 {
+		t.Fatalf("expected the rollouts of every worker, got %v", s.rollouts)
+	}
+
+	// a sequential search without terminals does not leave a stale flag for a parallel one
+	s.Search(newBoard(5, 5), 1, 0, 1, 50)
+	s.SetMaxRolloutMoves(0)
+	if s.SearchParallel(newBoard(5, 5), 1, 2, SearchBudget{MaxIters: 50}); !s.ReachedTerminal() {
+		t.Fatal("expected the parallel rollouts to reach terminals")
+	}
+}
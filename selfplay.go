@@ -0,0 +1,75 @@
+package mcts
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SelfPlayRecord is the training record of a single searched position.
+// Board is the searched board and Side the side to move. Moves are the root Moves and Policy
+// their share of the root child visits, which sums to 1. Outcome is the winner of the game,
+// 0 being a draw, which is known once the game is finished.
+type SelfPlayRecord struct {
+	Board   [][]int   `json:"board"`
+	Side    int       `json:"side"`
+	Moves   []Move    `json:"moves"`
+	Policy  []float64 `json:"policy"`
+	Outcome int       `json:"outcome"`
+}
+
+// SelfPlayRecorder collects a SelfPlayRecord for every search of a game and writes them as
+// JSON lines when the game is finished. Moves are encoded with encoding/json, so they should
+// have exported fields or implement json.Marshaler.
+type SelfPlayRecorder struct {
+	w       io.Writer
+	pending []SelfPlayRecord
+}
+
+// NewSelfPlayRecorder returns a SelfPlayRecorder that writes to w.
+func NewSelfPlayRecorder(w io.Writer) *SelfPlayRecorder {
+	return &SelfPlayRecorder{w: w}
+}
+
+// SetSelfPlayRecorder sets a recorder that receives a record after every search that expands
+// the root. A nil recorder, which is the default, disables recording. Parallel workers do not
+// record, but the combined search does, and determinized searches do not record since their
+// boards are samples.
+func (s *MCTS) SetSelfPlayRecorder(r *SelfPlayRecorder) {
+	s.recorder = r
+}
+
+// record adds the record of the search of root, whose board is board and side to move is side.
+func (r *SelfPlayRecorder) record(root *treeNode, board [][]int, side int) {
+	if len(root.children) == 0 {
+		return
+	}
+	rec := SelfPlayRecord{
+		Board:  copyBoard(board),
+		Side:   side,
+		Moves:  make([]Move, len(root.children)),
+		Policy: make([]float64, len(root.children)),
+	}
+	var total int64
+	for _, ch := range root.children {
+		total += ch.visits
+	}
+	for i, ch := range root.children {
+		rec.Moves[i] = ch.move
+		rec.Policy[i] = float64(ch.visits) / float64(total)
+	}
+	r.pending = append(r.pending, rec)
+}
+
+// Finish sets the outcome of the records of the game to winner, 0 being a draw, and writes
+// them to the writer of r. The records are cleared, so the recorder can be used for the next game.
+func (r *SelfPlayRecorder) Finish(winner int) error {
+	enc := json.NewEncoder(r.w)
+	for i := range r.pending {
+		r.pending[i].Outcome = winner
+		if err := enc.Encode(&r.pending[i]); err != nil {
+			return err
+		}
+	}
+	r.pending = r.pending[:0]
+	return nil
+}
@@ -0,0 +1,65 @@
+package mcts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestSelfPlayRecorder(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	var buf bytes.Buffer
+	r := NewSelfPlayRecorder(&buf)
+	s.SetSelfPlayRecorder(r)
+	board := newBoard(3, 3)
+	side, plies, winner := 1, 0, 0
+	for {
+		m, _ := s.SearchIterations(board, side, 200)
+		plies++
+		gameOver, w, err := ev.ApplyMove(board, side, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gameOver {
+			winner = w
+			break
+		}
+		side = ev.NextPlayer(side)
+	}
+	if err := r.Finish(winner); err != nil {
+		t.Fatal(err)
+	}
+	sc := bufio.NewScanner(&buf)
+	records := 0
+	for sc.Scan() {
+		var rec struct {
+			Board   [][]int   `json:"board"`
+			Side    int       `json:"side"`
+			Policy  []float64 `json:"policy"`
+			Outcome *int      `json:"outcome"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		records++
+		sum := 0.0
+		for _, p := range rec.Policy {
+			sum += p
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("expected a policy summing to 1, got %v", sum)
+		}
+		if rec.Outcome == nil || *rec.Outcome != winner {
+			t.Fatalf("expected the outcome %v, got %v", winner, rec.Outcome)
+		}
+		if len(rec.Board) != 3 || (rec.Side != 1 && rec.Side != 2) {
+			t.Fatal("expected the board and the side to move")
+		}
+	}
+	if records != plies {
+		t.Fatalf("expected %v records, got %v", plies, records)
+	}
+}
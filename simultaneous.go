@@ -0,0 +1,137 @@
+package mcts
+
+import "math"
+
+// SimultaneousEvaluator is an optional interface that an Evaluator can implement for
+// two-player games whose players move simultaneously, like rock-paper-scissors. Its Moves are
+// joint Moves that combine the actions of both players, and SplitMove returns the action of
+// the side to move and the action of its opponent that m combines. Expand and RandomMove return
+// joint Moves, ApplyMove applies both actions and NextPlayer returns its argument, since the
+// same side "moves" again at the next node, so every value is from the perspective of that side.
+type SimultaneousEvaluator interface {
+	SplitMove(m Move) (own, other Move)
+}
+
+// SetSimultaneousMoves sets whether the search handles simultaneous moves with decoupled UCT.
+// Instead of selecting the joint Move with the highest UCB value, each player selects its own
+// action with the UCB values of the statistics of its action summed over the joint Moves, and
+// the joint Move of both actions is followed. Ties between actions are broken with the generator
+// of random selection tie breaks. Minimax style proofs do not apply to simultaneous
+// moves, so only terminal nodes are proven. The final Move is a joint Move whose own action is
+// the most visited one, while RootActions returns the visits of every own action, which
+// approximate a mixed strategy. It requires the Evaluator to implement SimultaneousEvaluator
+// and is disabled by default.
+func (s *MCTS) SetSimultaneousMoves(simultaneous bool) {
+	_, ok := s.ev.(SimultaneousEvaluator)
+	s.simultaneous = simultaneous && ok
+}
+
+// actionStats are the statistics of an action summed over the joint Moves that contain it.
+type actionStats struct {
+	action   Move
+	visits   int64
+	winScore float64
+}
+
+// actions returns the statistics of the own actions of the children of n, or of the opponent
+// actions if other is true, from the perspective of the player of the action.
+func (s *MCTS) actions(n *treeNode, other bool) []actionStats {
+	sim := s.ev.(SimultaneousEvaluator)
+	var res []actionStats
+	for _, ch := range n.children {
+		a, opp := sim.SplitMove(ch.move)
+		sign := 1.0
+		if other {
+			a, sign = opp, -1
+		}
+		i := 0
+		for i < len(res) && !s.moveEqual(res[i].action, a) {
+			i++
+		}
+		if i == len(res) {
+			res = append(res, actionStats{action: a})
+		}
+		res[i].visits += ch.visits
+		res[i].winScore += sign * ch.winScore
+	}
+	return res
+}
+
+// highestUCBAction returns the action of stats with the highest UCB value, where c is the
+// factor of the exploration term. Ties are broken at random, since with a fixed order both
+// players would keep selecting matching actions in lockstep.
+func (s *MCTS) highestUCBAction(stats []actionStats, c float64) Move {
+	var res Move
+	maxVal := math.Inf(-1)
+	ties := 0
+	for _, a := range stats {
+		val := math.Inf(1)
+		if a.visits > 0 {
+			v := float64(a.visits)
+			val = a.winScore/v + c/math.Sqrt(v)
+		}
+		switch {
+		case res == nil || val > maxVal:
+			res, maxVal, ties = a.action, val, 1
+		case val == maxVal:
+			ties++
+			if s.tieRand().Intn(ties) == 0 {
+				res = a.action
+			}
+		}
+	}
+	return res
+}
+
+// simultaneousUCBChild returns the child of n whose joint Move combines the actions that both
+// players select, or nil if that joint Move is not in the tree.
+func (s *MCTS) simultaneousUCBChild(n *treeNode) *treeNode {
+	sim := s.ev.(SimultaneousEvaluator)
+	c := s.explorationTerm(n)
+	own := s.highestUCBAction(s.actions(n, false), c)
+	other := s.highestUCBAction(s.actions(n, true), c)
+	for _, ch := range n.children {
+		if a, b := sim.SplitMove(ch.move); s.moveEqual(a, own) && s.moveEqual(b, other) {
+			return ch
+		}
+	}
+	return nil
+}
+
+// simultaneousChild returns the most visited child of n among the children whose own action is
+// the most visited one.
+func (s *MCTS) simultaneousChild(n *treeNode) *treeNode {
+	sim := s.ev.(SimultaneousEvaluator)
+	var best actionStats
+	for _, a := range s.actions(n, false) {
+		if best.action == nil || a.visits > best.visits {
+			best = a
+		}
+	}
+	var res *treeNode
+	for _, ch := range n.children {
+		if own, _ := sim.SplitMove(ch.move); s.moveEqual(own, best.action) && (res == nil || ch.visits > res.visits) {
+			res = ch
+		}
+	}
+	return res
+}
+
+// RootActions returns the statistics of the own actions of the root of the last search with
+// simultaneous moves, summed over the joint Moves, in the order of their first joint Move. Play
+// can sample an action in proportion to its visits to follow the mixed strategy of the search.
+// It returns nil if simultaneous moves are disabled or there has been no search.
+func (s *MCTS) RootActions() []ChildStat {
+	if !s.simultaneous || s.root == nil {
+		return nil
+	}
+	var res []ChildStat
+	for _, a := range s.actions(s.root, false) {
+		st := ChildStat{Move: a.action, Visits: a.visits}
+		if a.visits > 0 {
+			st.Value = a.winScore / float64(a.visits)
+		}
+		res = append(res, st)
+	}
+	return res
+}
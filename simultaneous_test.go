@@ -0,0 +1,110 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// rpsMove is a joint Move of rock-paper-scissors, where the actions are 0 for rock, 1 for
+// paper, 2 for scissors and 3 for a dynamite that beats every other action.
+type rpsMove struct {
+	own, other int
+}
+
+func (m rpsMove) Eval() float64 { return 0 }
+
+// rpsAction is the action of a single player of rock-paper-scissors.
+type rpsAction int
+
+func (a rpsAction) Eval() float64 { return 0 }
+
+// rpsEval is a single round of rock-paper-scissors between side 1 and side 2, whose board is
+// the played flag, optionally with the dynamite action.
+type rpsEval struct {
+	actions int
+	r       *rand.Rand
+}
+
+func (e *rpsEval) beats(a, b int) bool {
+	if a == b {
+		return false
+	}
+	if a == 3 || b == 3 {
+		return a == 3
+	}
+	return (a-b+3)%3 == 1
+}
+
+func (e *rpsEval) RandomMove(board [][]int, side int) Move {
+	if board[0][0] != 0 {
+		return nil
+	}
+	return rpsMove{own: e.r.Intn(e.actions), other: e.r.Intn(e.actions)}
+}
+
+func (e *rpsEval) ApplyMove(board [][]int, side int, m Move) (bool, int, error) {
+	board[0][0] = 1
+	jm := m.(rpsMove)
+	switch {
+	case e.beats(jm.own, jm.other):
+		return true, side, nil
+	case e.beats(jm.other, jm.own):
+		return true, 3 - side, nil
+	}
+	return true, 0, nil
+}
+
+func (e *rpsEval) NextPlayer(side int) int { return side }
+func (e *rpsEval) PrevPlayer(side int) int { return side }
+
+func (e *rpsEval) SplitMove(m Move) (Move, Move) {
+	jm := m.(rpsMove)
+	return rpsAction(jm.own), rpsAction(jm.other)
+}
+
+func (e *rpsEval) Expand(board [][]int, side int) []Move {
+	if board[0][0] != 0 {
+		return nil
+	}
+	var res []Move
+	for a := 0; a < e.actions; a++ {
+		for b := 0; b < e.actions; b++ {
+			res = append(res, rpsMove{own: a, other: b})
+		}
+	}
+	return res
+}
+
+func TestSimultaneousMoves(t *testing.T) {
+	ev := &rpsEval{actions: 3, r: rand.New(rand.NewSource(1))}
+	s := New(ev, ev)
+	s.SetSimultaneousMoves(true)
+	s.SetSelectionRand(rand.New(rand.NewSource(1)))
+	m, visits := s.SearchWithBudget([][]int{{0}}, 1, SearchBudget{MaxIters: 3000})
+	if _, ok := m.(rpsMove); !ok {
+		t.Fatalf("expected a joint move, got %v", m)
+	}
+	actions := s.RootActions()
+	if len(actions) != 3 {
+		t.Fatalf("expected 3 own actions, got %v", len(actions))
+	}
+	for _, a := range actions {
+		if share := float64(a.Visits) / float64(visits); share < 0.2 || share > 0.47 {
+			t.Fatalf("expected a mixed strategy, got a share of %v for %v", share, a.Move)
+		}
+	}
+	if s.root.proven {
+		t.Fatalf("expected the root not to be proven")
+	}
+}
+
+func TestSimultaneousMovesDominantAction(t *testing.T) {
+	ev := &rpsEval{actions: 4, r: rand.New(rand.NewSource(1))}
+	s := New(ev, ev)
+	s.SetSimultaneousMoves(true)
+	s.SetSelectionRand(rand.New(rand.NewSource(1)))
+	m, _ := s.SearchWithBudget([][]int{{0}}, 1, SearchBudget{MaxIters: 3000})
+	if jm := m.(rpsMove); jm.own != 3 {
+		t.Fatalf("expected the dominant action, got %+v", jm)
+	}
+}
@@ -0,0 +1,118 @@
+package mcts
+
+// SetSolver sets whether backpropagation proves the outcomes of inner nodes from the proven
+// outcomes of their children, which is enabled by default. The solver requires the Expander to
+// return every legal Move, since a node whose generated Moves all lose would otherwise be proven
+// lost although a pruned Move saves it, and the search would stop early on the wrong proof. It
+// must be disabled for Expanders that prune Moves. Terminal nodes are proven regardless.
+func (s *MCTS) SetSolver(enabled bool) {
+	s.noSolver = !enabled
+}
+
+// solving reports whether backpropagation updates the proofs of inner nodes.
+func (s *MCTS) solving() bool {
+	return !s.noSolver && !s.simultaneous
+}
+
+// updateProof marks n as proven when its outcome follows from its children.
+// The player to move at n wins if one of the children is a proven win for that player.
+// If all children are proven, the outcome is the best one for that player, where a draw
+// is preferred over a loss. A loss is only proven if every child is won by the same player,
+// since the player to move has no preference among the winners of a game of more than two
+// players. draw is the winner of a draw.
+func (n *treeNode) updateProof(draw int) {
+	if n.proven || len(n.children) == 0 {
+		return
+	}
+	// moves that progressive widening has not added yet or that the move filter dropped are not
+	// proven
+	all := !n.hasPending() && !n.filtered
+	drawn, lost, mixed := false, false, false
+	winner := draw
+	for _, ch := range n.children {
+		if !ch.proven {
+			all = false
+			continue
+		}
+		if ch.provenWinner == ch.side {
+			n.proven = true
+			n.provenWinner = ch.side
+			return
+		}
+		switch {
+		case ch.provenWinner == draw:
+			drawn = true
+		case !lost:
+			lost, winner = true, ch.provenWinner
+		case ch.provenWinner != winner:
+			mixed = true
+		}
+	}
+	if !all || !drawn && mixed {
+		return
+	}
+	n.proven = true
+	if drawn {
+		n.provenWinner = draw
+	} else {
+		n.provenWinner = winner
+	}
+}
+
+// provenChild returns the most visited child of a proven n that achieves the proven outcome,
+// or nil if n is not proven or is a proven loss for the player to move, in which case every
+// child achieves it, since a loss is only proven if all children are won by the same player.
+func provenChild(n *treeNode, draw int) *treeNode {
+	if !n.proven || len(n.children) == 0 {
+		return nil
+	}
+	var res *treeNode
+	for _, ch := range n.children {
+		if !ch.proven || ch.provenWinner != n.provenWinner {
+			continue
+		}
+		if n.provenWinner != draw && n.provenWinner != ch.side {
+			return nil
+		}
+		if res == nil || ch.visits > res.visits {
+			res = ch
+		}
+	}
+	return res
+}
+
+// SetAvoidProvenLosses sets whether selection skips the children that are proven losses for
+// the side that plays them, for example because every reply to them wins for the opponent.
+// Such a child would otherwise keep being explored like any child with a low mean value,
+// although its value is already resolved to the minimum. The children of a node that are all
+// proven losses are still explored, since the node itself is then proven. It is disabled by
+// default.
+func (s *MCTS) SetAvoidProvenLosses(avoid bool) {
+	s.avoidLosses = avoid
+}
+
+// lost reports whether n is a proven loss for n.side.
+func (s *MCTS) lost(n *treeNode) bool {
+	return n.proven && n.provenWinner != n.side && n.provenWinner != s.draw
+}
+
+// highestUCBUnlostChild returns the child of n with the highest UCB value among the children
+// that are not proven losses, or nil if every child is one.
+func (s *MCTS) highestUCBUnlostChild(n *treeNode) *treeNode {
+	c := s.explorationTerm(n)
+	var res *treeNode
+	var maxVal float64
+	for _, ch := range n.children {
+		if s.lost(ch) {
+			continue
+		}
+		if ch.visits == 0 {
+			return ch
+		}
+		mean, inv := s.ucbTerms(ch)
+		if val := mean + c*inv; res == nil || val > maxVal {
+			res, maxVal = ch, val
+		}
+	}
+	return res
+}
@@ -0,0 +1,198 @@
+package mcts
+
+import "testing"
+
+func TestSearchProvenWin(t *testing.T) {
+	// X creates a double threat with (1, 0) or (2, 0) and wins in 3 moves.
+	board := [][]int{
+		{1, 2, 0},
+		{0, 1, 0},
+		{0, 0, 2},
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	res := s.SearchWithStats(board, 1, 0, 0, 2000)
+	if !res.Proven || res.ProvenOutcome != 1 {
+		t.Fatalf("expected a proven win for X, got proven %v with outcome %v", res.Proven, res.ProvenOutcome)
+	}
+	if !s.root.proven || s.root.provenWinner != 1 {
+		t.Fatal("expected the root to be a proven win for X")
+	}
+	if len(res.Stats) != 5 {
+		t.Fatalf("expected 5 root moves, got %v", len(res.Stats))
+	}
+}
+
+func TestSearchNotProven(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	res := s.SearchWithStats(newBoard(3, 3), 1, 0, 0, 100)
+	if res.Proven {
+		t.Fatal("did not expect a proof on an empty board with a tiny budget")
+	}
+}
+
+func TestStopAfterProof(t *testing.T) {
+	// a tictactoe position that is a draw with best play
+	board := func() [][]int {
+		return [][]int{
+			{0, 0, 0},
+			{0, 2, 0},
+			{1, 1, 2},
+		}
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	m, _ := s.SearchIterations(board(), 1, 20000)
+	if !s.root.proven || s.root.provenWinner != 0 {
+		t.Fatal("expected a proven draw")
+	}
+	for _, ch := range s.root.children {
+		if ch.move == m && (!ch.proven || ch.provenWinner != 0) {
+			t.Fatal("expected a move that holds the draw")
+		}
+	}
+	if s.rollouts >= 20000 {
+		t.Fatal("expected the search to stop once the root is proven")
+	}
+	s = New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	s.SearchIterations(board(), 1, 20000)
+	if s.rollouts != 20000 {
+		t.Fatalf("expected the full budget, got %v iterations", s.rollouts)
+	}
+}
+
+// sentinelEval is a tictactoe evaluator that reports draws as -1.
+type sentinelEval struct {
+	*tttEval
+}
+
+func (e *sentinelEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	gameOver, winner, err := e.tttEval.ApplyMove(board, currentPlayerSide, m)
+	if gameOver && winner == 0 {
+		winner = -1
+	}
+	return gameOver, winner, err
+}
+
+func (e *sentinelEval) IsTerminal(board [][]int) (bool, int) {
+	gameOver, winner := e.finished(board)
+	if gameOver && winner == 0 {
+		winner = -1
+	}
+	return gameOver, winner
+}
+
+func TestDrawSentinel(t *testing.T) {
+	board := [][]int{
+		{0, 0, 0},
+		{0, 2, 0},
+		{1, 1, 2},
+	}
+	ev := &sentinelEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetDrawSentinel(-1)
+	s.SearchIterations(board, 1, 20000)
+	if !s.root.proven || s.root.provenWinner != -1 {
+		t.Fatalf("expected a proven draw, got proven %v with outcome %v", s.root.proven, s.root.provenWinner)
+	}
+	if balance := s.RolloutBalance(); balance[-1] == 0 || balance[0] != 0 {
+		t.Fatalf("expected draws under -1, got %v", balance)
+	}
+	if v, _ := s.Evaluate(board, 1, SearchBudget{MaxIters: 2000}); v < -0.5 {
+		t.Fatalf("expected draws to be scored as draws, got a value of %v", v)
+	}
+	if v := s.sign(1, -1); v != 0 {
+		t.Fatalf("expected a draw reward of 0, got %v", v)
+	}
+	if v := New(ev, ev).sign(1, -1); v != -1 {
+		t.Fatalf("expected -1 to be a win for another side without the sentinel, got %v", v)
+	}
+}
+
+func TestAvoidProvenLosses(t *testing.T) {
+	// O threatens to win with (1, 2), so every other move of X loses
+	board := func() [][]int {
+		return [][]int{
+			{1, 0, 0},
+			{2, 2, 0},
+			{1, 0, 0},
+		}
+	}
+	lostVisits := func(avoid bool) int64 {
+		ev := newTTTEval(3, 1)
+		s := New(ev, ev)
+		s.SetKeepSearchingAfterProof(true)
+		s.SetAvoidProvenLosses(avoid)
+		s.SearchIterations(board(), 1, 2000)
+		var res int64
+		for _, ch := range s.root.children {
+			if mov := ch.move.(*tttMove); mov.i != 1 || mov.j != 2 {
+				if avoid && !s.lost(ch) {
+					t.Fatal("expected every move but the block to be a proven loss")
+				}
+				res += ch.visits
+			}
+		}
+		return res
+	}
+	with, without := lostVisits(true), lostVisits(false)
+	if with*2 >= without {
+		t.Fatalf("expected proven losses to be skipped, got %v visits with and %v without", with, without)
+	}
+}
+
+// pruningEval is a tictactoe evaluator whose Expander never lets X play (0, 2).
+type pruningEval struct {
+	*tttEval
+}
+
+func (e *pruningEval) Expand(board [][]int, side int) []Move {
+	var res []Move
+	for _, m := range e.tttEval.Expand(board, side) {
+		if mov := m.(*tttMove); side != 1 || mov.i != 0 || mov.j != 2 {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func TestSolverDisabled(t *testing.T) {
+	// X must block at (0, 2), which the Expander prunes, so every generated move loses
+	board := func() [][]int {
+		return [][]int{
+			{2, 2, 0},
+			{1, 0, 0},
+			{1, 0, 0},
+		}
+	}
+	ev := &pruningEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SearchIterations(board(), 1, 500)
+	if !s.root.proven || s.root.provenWinner != 2 {
+		t.Fatal("expected the solver to prove the pruned position lost")
+	}
+	s = New(ev, ev)
+	s.SetSolver(false)
+	s.SearchIterations(board(), 1, 500)
+	if s.root.proven || s.rollouts != 500 {
+		t.Fatalf("expected no proof without the solver, got %v rollouts", s.rollouts)
+	}
+}
+
+func TestProofSeveralWinners(t *testing.T) {
+	// player 1 moves at n of a three player game and loses to player 2 or 3
+	n := &treeNode{side: 3}
+	for _, w := range []int{2, 3} {
+		n.children = append(n.children, &treeNode{parent: n, side: 1, proven: true, provenWinner: w})
+	}
+	n.updateProof(0)
+	if n.proven {
+		t.Fatalf("expected no proof without a single winner, got %v", n.provenWinner)
+	}
+	n.children[1].provenWinner = 2
+	if n.updateProof(0); !n.proven || n.provenWinner != 2 {
+		t.Fatalf("expected a proven win for player 2, got %v", n.provenWinner)
+	}
+}
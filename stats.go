@@ -0,0 +1,216 @@
+package mcts
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ChildStat holds the statistics of a root move after a search.
+// Value is the mean evaluation of the move from the perspective of the side that plays it.
+// Visits also counts the visits that expansions add, while SubtreeVisits is the number of
+// iterations whose rollout started in the subtree of the move. Time is the time spent in those
+// iterations and is only measured when subtree timing is enabled. Reason is the game over
+// reason of a Move that ends the game, see GameOverReasoner.
+type ChildStat struct {
+	Move          Move
+	Visits        int64
+	Value         float64
+	SubtreeVisits int64
+	Time          time.Duration
+	Reason        GameOverReason
+}
+
+// SearchResult is the detailed result of a search.
+// Move is the chosen Move, or nil if the board is already a finished game.
+// Value is the mean evaluation of Move from the perspective of the searched side and
+// Visits is the number of root visits. Stats holds the statistics of all root Moves.
+// Proven is true if the outcome of Move is known regardless of the remaining moves,
+// in which case ProvenOutcome is the winner, 0 being a draw.
+// Underexplored is true if Move has fewer visits than set with SetMinTrustVisits, so that its
+// value is too noisy to rely on. LowConfidence is true if no rollout ended the game, so that the
+// values carry no signal, see ReachedTerminal.
+type SearchResult struct {
+	Move          Move
+	Value         float64
+	Visits        int64
+	Stats         []ChildStat
+	Proven        bool
+	ProvenOutcome int
+	Underexplored bool
+	LowConfidence bool
+}
+
+// SearchWithStats searches like Search and returns a detailed result.
+func (s *MCTS) SearchWithStats(board [][]int, side int, duration time.Duration, maxDepth, maxIters int) SearchResult {
+	root := s.search(board, side, SearchBudget{Duration: duration, MaxDepth: maxDepth, MaxIters: maxIters})
+	if root.gameOver {
+		return SearchResult{}
+	}
+	res := SearchResult{
+		Visits: root.visits,
+		Stats:  childStats(root),
+	}
+	if s.includeUnvisited {
+		res.Stats = s.withUnvisited(root, res.Stats)
+	}
+	if len(root.children) == 0 {
+		res.Move = s.fallbackMove(root, side)
+		return res
+	}
+	best := s.bestChild(root)
+	res.Move = best.move
+	res.Value = best.value()
+	res.Proven = best.proven
+	res.ProvenOutcome = best.provenWinner
+	res.Underexplored = best.visits < s.minTrustVisits
+	res.LowConfidence = !s.ReachedTerminal()
+	if s.calibration > 0 {
+		res.Value = s.calibrated(res.Value)
+		for i := range res.Stats {
+			res.Stats[i].Value = s.calibrated(res.Stats[i].Value)
+		}
+	}
+	return res
+}
+
+// SetValueCalibration sets the scale of a logistic that maps the values reported by
+// SearchWithStats and Evaluate to win probability like values in [0, 1], which makes analyses
+// comparable across positions and configurations with different reward scales. A value v is
+// reported as 1 / (1 + exp(-scale*v)), so 0 maps to 0.5. The internal values of the search are
+// not affected. The default scale of 0 reports the raw values.
+func (s *MCTS) SetValueCalibration(scale float64) {
+	s.calibration = scale
+}
+
+// calibrated returns the reported value of v.
+func (s *MCTS) calibrated(v float64) float64 {
+	return 1 / (1 + math.Exp(-s.calibration*v))
+}
+
+// SetSubtreeTiming sets whether the time of every iteration is attributed to the nodes on
+// its path, which is reported in ChildStat.Time. It is disabled by default to avoid the cost
+// of reading the clock.
+func (s *MCTS) SetSubtreeTiming(timing bool) {
+	s.timing = timing
+}
+
+// SetMinTrustVisits sets the number of visits below which the value of a move is not trusted.
+// Moves with fewer visits are ignored when the final Move is chosen by value, which is the case
+// in Minimax backup mode and for the distance tiebreak, unless no move has enough visits.
+// A Move chosen with fewer visits is reported as underexplored in SearchResult.
+// The default of 0 trusts every move.
+func (s *MCTS) SetMinTrustVisits(visits int64) {
+	s.minTrustVisits = visits
+}
+
+// TopMoves returns up to n root moves of the last search, sorted by visits and then by value
+// in descending order. It returns nil if no search has been run yet.
+func (s *MCTS) TopMoves(n int) []ChildStat {
+	if s.root == nil || n <= 0 {
+		return nil
+	}
+	stats := childStats(s.root)
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Visits != stats[j].Visits {
+			return stats[i].Visits > stats[j].Visits
+		}
+		return stats[i].Value > stats[j].Value
+	})
+	if n < len(stats) {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// SetIncludeUnvisited sets whether SearchWithStats reports every root Move returned by Expand,
+// including the Moves that are not in the tree, for example because of progressive widening or
+// a tiny budget. Those Moves are reported after the tree Moves with zero statistics.
+func (s *MCTS) SetIncludeUnvisited(include bool) {
+	s.includeUnvisited = include
+}
+
+// withUnvisited appends the statistics of the root Moves that are not children of root to stats.
+func (s *MCTS) withUnvisited(root *treeNode, stats []ChildStat) []ChildStat {
+	for _, m := range s.expandAt(s.boardOf(root), root.toMove, 0) {
+		found := false
+		for _, ch := range root.children {
+			if s.moveEqual(ch.move, m) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stats = append(stats, ChildStat{Move: m})
+		}
+	}
+	return stats
+}
+
+func childStats(n *treeNode) []ChildStat {
+	res := make([]ChildStat, len(n.children))
+	for i, ch := range n.children {
+		res[i] = childStat(ch)
+	}
+	return res
+}
+
+func childStat(ch *treeNode) ChildStat {
+	return ChildStat{
+		Move:          ch.move,
+		Visits:        ch.visits,
+		Value:         ch.value(),
+		SubtreeVisits: ch.iters,
+		Time:          ch.elapsed,
+		Reason:        ch.reason,
+	}
+}
+
+// AnalyzeProgression searches board in stages and returns the statistics of the best Move
+// after each stage. budgets are cumulative iteration counts, so the tree is reused between
+// stages and a stage runs the difference to the previous budget. This shows how the best Move
+// stabilizes as the search grows.
+func (s *MCTS) AnalyzeProgression(board [][]int, side int, budgets []int) []ChildStat {
+	reuse := s.reuseTree
+	if !reuse {
+		s.root = nil
+	}
+	s.reuseTree = true
+	defer func() {
+		s.reuseTree = reuse
+	}()
+	res := make([]ChildStat, 0, len(budgets))
+	done := 0
+	for _, b := range budgets {
+		if b > done {
+			s.SearchIterations(board, side, b-done)
+			done = b
+		}
+		if s.root == nil || len(s.root.children) == 0 {
+			res = append(res, ChildStat{})
+			continue
+		}
+		res = append(res, childStat(s.bestChild(s.root)))
+	}
+	return res
+}
+
+// VisitHeatmap returns the visits of the root Moves of the last search on a grid of rows by cols
+// cells for the visualization of board games, where locate returns the cell of a Move. Moves
+// whose cell is outside the grid, like a pass, are left out and Moves on the same cell add up.
+// The grid is all zeros if no search has been run yet.
+func (s *MCTS) VisitHeatmap(locate func(Move) (int, int), rows, cols int) [][]int64 {
+	res := make([][]int64, rows)
+	for i := range res {
+		res[i] = make([]int64, cols)
+	}
+	if s.root == nil {
+		return res
+	}
+	for _, ch := range s.root.children {
+		if r, c := locate(ch.move); r >= 0 && r < rows && c >= 0 && c < cols {
+			res[r][c] += ch.visits
+		}
+	}
+	return res
+}
@@ -0,0 +1,188 @@
+package mcts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTopMoves(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if s.TopMoves(3) != nil {
+		t.Fatal("expected no moves before a search")
+	}
+	s.Search(newBoard(3, 3), 1, time.Hour, 0, 500)
+	top := s.TopMoves(3)
+	if len(top) != 3 {
+		t.Fatalf("expected 3 moves, got %v", len(top))
+	}
+	for i := 1; i < len(top); i++ {
+		if top[i].Visits > top[i-1].Visits {
+			t.Fatalf("moves are not sorted by visits: %v", top)
+		}
+	}
+	if top[0].Move != bestChild(s.root).move {
+		t.Fatal("first move does not match the best child")
+	}
+	if all := s.TopMoves(100); len(all) != 9 {
+		t.Fatalf("expected all 9 moves, got %v", len(all))
+	}
+}
+
+func TestAnalyzeProgression(t *testing.T) {
+	// X wins at (0, 2).
+	board := [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	stages := s.AnalyzeProgression(board, 1, []int{10, 100, 1000})
+	if len(stages) != 3 {
+		t.Fatalf("expected 3 stages, got %v", len(stages))
+	}
+	for i := 1; i < len(stages); i++ {
+		if stages[i].Visits <= stages[i-1].Visits {
+			t.Fatalf("expected increasing visits, got %v", stages)
+		}
+	}
+	for _, st := range stages[1:] {
+		if m := st.Move.(*tttMove); m.i != 0 || m.j != 2 {
+			t.Fatalf("expected the winning move to be stable, got (%v, %v)", m.i, m.j)
+		}
+	}
+	if s.root.visits < 1000 {
+		t.Fatalf("expected cumulative root visits, got %v", s.root.visits)
+	}
+}
+
+func TestSubtreeVisits(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetSubtreeTiming(true)
+	t0 := time.Now()
+	s.Search(newBoard(3, 3), 1, 0, 0, 300)
+	elapsed := time.Since(t0)
+	var sum int64
+	var spent time.Duration
+	for _, st := range s.TopMoves(9) {
+		sum += st.SubtreeVisits
+		spent += st.Time
+	}
+	if s.root.iters != 300 || sum != 300 {
+		t.Fatalf("expected 300 iterations in the root children, got %v of %v", sum, s.root.iters)
+	}
+	if spent <= 0 || spent > elapsed {
+		t.Fatalf("expected subtree time between 0 and %v, got %v", elapsed, spent)
+	}
+}
+
+func TestMinTrustVisits(t *testing.T) {
+	ev := newTTTEval(4, 1)
+	s := New(ev, ev)
+	s.SetMinTrustVisits(20)
+	if res := s.SearchWithStats(newBoard(4, 4), 1, 0, 0, 10); !res.Underexplored {
+		t.Fatalf("expected a tiny budget to be underexplored, got %v visits", res.Visits)
+	}
+	if res := s.SearchWithStats(newBoard(4, 4), 1, 0, 0, 2000); res.Underexplored {
+		t.Fatal("expected a large budget to be trusted")
+	}
+}
+
+func TestMinTrustVisitsMinimax(t *testing.T) {
+	root := &treeNode{side: 2}
+	noisy := &treeNode{parent: root, side: 1, visits: 2, winScore: 2}
+	solid := &treeNode{parent: root, side: 1, visits: 50, winScore: 20}
+	root.children = []*treeNode{noisy, solid}
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetBackupMode(Minimax)
+	if s.bestChild(root) != noisy {
+		t.Fatal("expected the highest value without a threshold")
+	}
+	s.SetMinTrustVisits(10)
+	if s.bestChild(root) != solid {
+		t.Fatal("expected the low visit move to be ignored")
+	}
+}
+
+func TestIncludeUnvisited(t *testing.T) {
+	ev := &rankedEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetProgressiveWidening(1, 0.5)
+	if res := s.SearchWithStats(newBoard(3, 3), 1, 0, 0, 2); len(res.Stats) >= 9 {
+		t.Fatalf("expected a subset of the root moves, got %v", len(res.Stats))
+	}
+	s.SetIncludeUnvisited(true)
+	res := s.SearchWithStats(newBoard(3, 3), 1, 0, 0, 2)
+	if len(res.Stats) != 9 {
+		t.Fatalf("expected every legal root move, got %v", len(res.Stats))
+	}
+	seen := make(map[[2]int]bool)
+	for _, st := range res.Stats {
+		m := st.Move.(*tttMove)
+		seen[[2]int{m.i, m.j}] = true
+	}
+	if len(seen) != 9 {
+		t.Fatalf("expected distinct moves, got %v", len(seen))
+	}
+	last := res.Stats[len(res.Stats)-1]
+	if last.Visits != 0 || last.Value != 0 {
+		t.Fatalf("expected zero statistics for a move outside the tree, got %+v", last)
+	}
+}
+
+func TestValueCalibration(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetValueCalibration(2)
+	if v := s.calibrated(0); v != 0.5 {
+		t.Fatalf("expected a value of 0 to map to 0.5, got %v", v)
+	}
+	if v := s.calibrated(0.3); v <= 0.5 || v >= 1 {
+		t.Fatalf("expected a positive value to map above 0.5, got %v", v)
+	}
+	if v := s.calibrated(-0.3); v >= 0.5 || v <= 0 {
+		t.Fatalf("expected a negative value to map below 0.5, got %v", v)
+	}
+	// side 1 wins by completing the first row
+	board := [][]int{{1, 1, 0}, {2, 2, 0}, {0, 0, 0}}
+	res := s.SearchWithStats(board, 1, 0, 0, 200)
+	if res.Value <= 0.5 || res.Value > 1 {
+		t.Fatalf("expected a calibrated winning value above 0.5, got %v", res.Value)
+	}
+	for _, st := range res.Stats {
+		if st.Value < 0 || st.Value > 1 {
+			t.Fatalf("expected calibrated move values in [0, 1], got %v", st.Value)
+		}
+	}
+}
+
+func TestVisitHeatmap(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	locate := func(m Move) (int, int) {
+		tm := m.(*tttMove)
+		return tm.i, tm.j
+	}
+	if grid := s.VisitHeatmap(locate, 3, 3); len(grid) != 3 || grid[1][1] != 0 {
+		t.Fatal("expected an empty heatmap before searching")
+	}
+	board := [][]int{{1, 0, 0}, {0, 2, 0}, {0, 0, 0}}
+	_, visits := s.Search(board, 1, 0, 0, 300)
+	grid := s.VisitHeatmap(locate, 3, 3)
+	var sum int64
+	for i, row := range grid {
+		for j, v := range row {
+			if board[i][j] != 0 && v != 0 {
+				t.Fatalf("expected no visits on the occupied square %v,%v, got %v", i, j, v)
+			}
+			sum += v
+		}
+	}
+	if sum != visits {
+		t.Fatalf("expected the heatmap to sum to the %v root visits, got %v", visits, sum)
+	}
+}
@@ -0,0 +1,76 @@
+package mcts
+
+// Canonicalizer is an optional interface that an Evaluator can implement for games with
+// symmetries. CanonicalKey returns the same key for all boards that are equivalent, for
+// example under rotations and reflections of the board.
+type Canonicalizer interface {
+	CanonicalKey(board [][]int) string
+}
+
+// Hasher is an optional interface that an Evaluator can implement to hash boards incrementally,
+// for example with Zobrist hashing. Hash returns the hash of board and HashAfter returns the
+// hash of the board after side plays m on a board whose hash is prevHash, which must equal the
+// Hash of that board. When implemented, equivalent nodes for shared statistics are found by
+// hash in O(1) per node instead of by CanonicalKey, so the hash should fold symmetric boards
+// together if symmetries are to be shared.
+type Hasher interface {
+	Hash(board [][]int) uint64
+	HashAfter(prevHash uint64, m Move, side int) uint64
+}
+
+// stateKey identifies equivalent nodes, which have the same hash or canonical key and the
+// same side.
+type stateKey struct {
+	hash uint64
+	key  string
+	side int
+}
+
+// nodeStats are statistics shared by equivalent nodes.
+type nodeStats struct {
+	visits   int64
+	winScore float64
+}
+
+// SetSharedStats sets whether equivalent nodes anywhere in the tree share their statistics
+// for selection. Nodes are equivalent if the CanonicalKey of their boards, or their Hash if the
+// Evaluator implements Hasher, and the side that played their Move are equal, which folds
+// symmetric positions and transpositions together. It requires the Evaluator to implement
+// Canonicalizer or Hasher and is disabled by default.
+func (s *MCTS) SetSharedStats(share bool) {
+	_, canonical := s.ev.(Canonicalizer)
+	_, hashed := s.ev.(Hasher)
+	s.sharing = share && (canonical || hashed)
+}
+
+// DistinctStates returns the number of distinct canonical states in the current tree
+// when shared statistics are enabled, and 0 otherwise.
+func (s *MCTS) DistinctStates() int {
+	return len(s.sharedStats)
+}
+
+// shareStats links n to the shared statistics of its canonical state.
+func (s *MCTS) shareStats(n *treeNode) {
+	key := stateKey{side: n.side}
+	if _, ok := s.ev.(Hasher); ok {
+		key.hash = n.hash
+	} else {
+		key.key = s.ev.(Canonicalizer).CanonicalKey(s.boardOf(n))
+	}
+	if s.sharedStats == nil {
+		s.sharedStats = make(map[stateKey]*nodeStats)
+	}
+	st, ok := s.sharedStats[key]
+	if !ok {
+		st = &nodeStats{}
+		s.sharedStats[key] = st
+	}
+	n.shared = st
+}
+
+// setRootHash sets the hash of root from its board if the Evaluator implements Hasher.
+func (s *MCTS) setRootHash(root *treeNode) {
+	if h, ok := s.ev.(Hasher); ok {
+		root.hash = h.Hash(root.board)
+	}
+}
@@ -0,0 +1,135 @@
+package mcts
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// symmetricEval is a tictactoe evaluator whose canonical key is the smallest key of the
+// 8 rotations and reflections of the board.
+type symmetricEval struct {
+	*tttEval
+}
+
+func (e *symmetricEval) NewScratch() EvaluatorScratch {
+	return &symmetricEval{tttEval: newTTTEval(e.target, e.r.Int63())}
+}
+
+func (e *symmetricEval) CanonicalKey(board [][]int) string {
+	n := len(board)
+	best := ""
+	for k := 0; k < 8; k++ {
+		key := ""
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				r, c := i, j
+				if k&1 != 0 {
+					r, c = c, r
+				}
+				if k&2 != 0 {
+					r = n - 1 - r
+				}
+				if k&4 != 0 {
+					c = n - 1 - c
+				}
+				key += fmt.Sprint(board[r][c])
+			}
+		}
+		if best == "" || key < best {
+			best = key
+		}
+	}
+	return best
+}
+
+func TestSharedStats(t *testing.T) {
+	ev := &symmetricEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetSharedStats(true)
+	s.Search(newBoard(3, 3), 1, 0, 0, 500)
+	nodes := countNodes(s.root) - 1
+	if d := s.DistinctStates(); d == 0 || d >= nodes {
+		t.Fatalf("expected fewer distinct states than the %v nodes, got %v", nodes, d)
+	}
+	distinct := make(map[*nodeStats]bool)
+	for _, ch := range s.root.children {
+		distinct[ch.shared] = true
+	}
+	if len(distinct) != 3 {
+		t.Fatalf("expected the corner, edge and center moves to share statistics, got %v groups", len(distinct))
+	}
+	if New(newTTTEval(3, 1), ev).DistinctStates() != 0 {
+		t.Fatal("expected no distinct states without sharing")
+	}
+}
+
+// zobristEval is a tictactoe evaluator with Zobrist hashes of its boards.
+type zobristEval struct {
+	*tttEval
+	keys [][][3]uint64
+}
+
+func newZobristEval(rows, cols int) *zobristEval {
+	r := rand.New(rand.NewSource(1))
+	e := &zobristEval{tttEval: newTTTEval(3, 1), keys: make([][][3]uint64, rows)}
+	for i := range e.keys {
+		e.keys[i] = make([][3]uint64, cols)
+		for j := range e.keys[i] {
+			for side := range e.keys[i][j] {
+				e.keys[i][j][side] = r.Uint64()
+			}
+		}
+	}
+	return e
+}
+
+func (e *zobristEval) Hash(board [][]int) uint64 {
+	var res uint64
+	for i, row := range board {
+		for j, v := range row {
+			if v != 0 {
+				res ^= e.keys[i][j][v]
+			}
+		}
+	}
+	return res
+}
+
+func (e *zobristEval) HashAfter(prevHash uint64, m Move, side int) uint64 {
+	mov := m.(*tttMove)
+	return prevHash ^ e.keys[mov.i][mov.j][side]
+}
+
+func TestHasher(t *testing.T) {
+	ev := newZobristEval(3, 3)
+	board := newBoard(3, 3)
+	h := ev.Hash(board)
+	side := 1
+	for _, m := range []*tttMove{{i: 1, j: 1}, {i: 0, j: 0}, {i: 2, j: 1}, {i: 0, j: 2}} {
+		m.side = side
+		h = ev.HashAfter(h, m, side)
+		ev.ApplyMove(board, side, m)
+		if h != ev.Hash(board) {
+			t.Fatal("expected the incremental hash to match the full hash")
+		}
+		side = ev.NextPlayer(side)
+	}
+
+	s := New(ev, ev)
+	s.SetSharedStats(true)
+	s.SearchIterations(newBoard(3, 3), 1, 500)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.hash != ev.Hash(s.boardOf(n)) {
+			t.Fatal("expected the node hash to match the hash of its board")
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(s.root)
+	if d := s.DistinctStates(); d == 0 || d >= countNodes(s.root)-1 {
+		t.Fatalf("expected transpositions to share statistics, got %v distinct states", d)
+	}
+}
@@ -0,0 +1,67 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// TieBreak determines how selection chooses among children with equal UCB values, which
+// includes the unvisited children of a node.
+type TieBreak int
+
+const (
+	// FirstTie selects the child that was expanded first. This is the default.
+	FirstTie TieBreak = iota
+	// RandomTie selects uniformly at random with the selection random number generator.
+	RandomTie
+	// PriorTie selects the child whose Move has the highest Eval, and the child that was
+	// expanded first among equal ones.
+	PriorTie
+)
+
+// SetSelectionTieBreak sets how selection breaks ties between children with equal UCB values
+// throughout the tree. RandomTie uses the generator set with SetSelectionRand, or a generator
+// seeded with the current time if it is not set. It does not apply along with
+// SetAvoidProvenLosses.
+func (s *MCTS) SetSelectionTieBreak(tb TieBreak) {
+	s.tieBreak = tb
+}
+
+// tieBrokenUCBChild returns the child of n with the highest UCB value, breaking ties
+// according to the tie break mode.
+func (s *MCTS) tieBrokenUCBChild(n *treeNode) *treeNode {
+	c := s.explorationTerm(n)
+	var res *treeNode
+	var maxVal float64
+	ties := 0
+	for _, ch := range n.children {
+		val := math.Inf(1)
+		if ch.visits > 0 {
+			mean, inv := s.ucbTerms(ch)
+			val = mean + c*inv
+		}
+		switch {
+		case res == nil || val > maxVal:
+			res, maxVal, ties = ch, val, 1
+		case val == maxVal:
+			ties++
+			if s.tieBreak == RandomTie && s.tieRand().Intn(ties) == 0 ||
+				s.tieBreak == PriorTie && ch.move.Eval() > res.move.Eval() {
+				res = ch
+			}
+		}
+	}
+	return res
+}
+
+// tieRand returns the random number generator of random tie breaks.
+func (s *MCTS) tieRand() *rand.Rand {
+	if s.selectionRand != nil {
+		return s.selectionRand
+	}
+	if s.tieRng == nil {
+		s.tieRng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return s.tieRng
+}
@@ -0,0 +1,14 @@
+package mcts
+
+// TrajectorySink receives the move sequence of every rollout together with its outcome.
+// moves starts with the tree moves leading from the searched board to the rolled out node,
+// followed by the random playout moves. winner is the draw sentinel for a draw.
+type TrajectorySink interface {
+	Record(moves []Move, winner int)
+}
+
+// SetTrajectorySink sets the sink that is called at the end of each rollout.
+// A nil sink, which is the default, disables recording.
+func (s *MCTS) SetTrajectorySink(ts TrajectorySink) {
+	s.ts = ts
+}
@@ -0,0 +1,111 @@
+package mcts
+
+// TranspositionKey identifies a position in a TranspositionStore by the CanonicalKey of its
+// board and the side that played the Move leading to it.
+type TranspositionKey struct {
+	Board string
+	Side  int
+}
+
+// TranspositionStats are the statistics of a position in a TranspositionStore. WinScore is
+// the sum of the rewards of the Visits from the perspective of the Side of the key.
+type TranspositionStats struct {
+	Visits   int64
+	WinScore float64
+}
+
+// TranspositionStore is a persistent store of position statistics that outlives a search, for
+// example to accumulate knowledge over many games. Get returns the stored statistics of key, if
+// any, and Put replaces them.
+type TranspositionStore interface {
+	Get(key TranspositionKey) (TranspositionStats, bool)
+	Put(key TranspositionKey, stats TranspositionStats)
+}
+
+// SetTranspositionStore sets the store that warm starts the statistics of expanded tree nodes
+// and that receives the statistics of the tree after every search. A node whose position is in
+// the store starts with its stored visits and score, which are also added to its ancestors like
+// with WarmStart up to the nearest ancestor that was loaded itself and so already counts them.
+// The store is then updated with the statistics of the node, so the knowledge accumulates over
+// searches. Positions reached by several nodes are stored from the most visited one. The workers
+// of SearchParallel do not use the store, which need not be safe for concurrent use, and the
+// statistics of their trees are added to the stored ones once after the search.
+// It requires the Evaluator to implement Canonicalizer and is disabled with a nil store.
+func (s *MCTS) SetTranspositionStore(store TranspositionStore) {
+	if _, ok := s.ev.(Canonicalizer); !ok {
+		store = nil
+	}
+	s.store = store
+}
+
+// transpositionKey returns the key of n in the transposition store.
+func (s *MCTS) transpositionKey(n *treeNode) TranspositionKey {
+	return TranspositionKey{Board: s.ev.(Canonicalizer).CanonicalKey(s.boardOf(n)), Side: n.side}
+}
+
+// loadTransposition adds the stored statistics of the position of n to n and to its ancestors
+// up to the nearest loaded one, whose stored statistics already include those of n.
+func (s *MCTS) loadTransposition(n *treeNode) {
+	st, ok := s.store.Get(s.transpositionKey(n))
+	if !ok || st.Visits <= 0 {
+		return
+	}
+	n.loaded = true
+	for a := n; a != nil && (a == n || !a.loaded); a = a.parent {
+		score := st.WinScore
+		if a.side != n.side {
+			score = -score
+		}
+		a.visits += st.Visits
+		a.winScore += score
+		a.sqScore += score * score / float64(st.Visits)
+	}
+}
+
+// storeTranspositions puts the statistics of the visited nodes of root into the transposition store.
+func (s *MCTS) storeTranspositions(root *treeNode) {
+	for key, st := range s.transpositions(root) {
+		s.store.Put(key, st)
+	}
+}
+
+// storeMergedTranspositions adds the statistics of the visited nodes of roots, whose trees did
+// not load from the transposition store, to the stored statistics.
+func (s *MCTS) storeMergedTranspositions(roots []*treeNode) {
+	total := make(map[TranspositionKey]TranspositionStats)
+	for _, r := range roots {
+		for key, st := range s.transpositions(r) {
+			t := total[key]
+			t.Visits += st.Visits
+			t.WinScore += st.WinScore
+			total[key] = t
+		}
+	}
+	for key, st := range total {
+		if prev, ok := s.store.Get(key); ok {
+			st.Visits += prev.Visits
+			st.WinScore += prev.WinScore
+		}
+		s.store.Put(key, st)
+	}
+}
+
+// transpositions returns the statistics of the most visited node of every position of root.
+func (s *MCTS) transpositions(root *treeNode) map[TranspositionKey]TranspositionStats {
+	best := make(map[TranspositionKey]TranspositionStats)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.visits <= 0 {
+			return
+		}
+		key := s.transpositionKey(n)
+		if st, ok := best[key]; !ok || n.visits > st.Visits {
+			best[key] = TranspositionStats{Visits: n.visits, WinScore: n.winScore}
+		}
+		for _, ch := range n.children {
+			walk(ch)
+		}
+	}
+	walk(root)
+	return best
+}
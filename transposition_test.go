@@ -0,0 +1,141 @@
+package mcts
+
+import "testing"
+
+// mapStore is an in-memory TranspositionStore.
+type mapStore struct {
+	stats map[TranspositionKey]TranspositionStats
+	gets  int
+}
+
+func (m *mapStore) Get(key TranspositionKey) (TranspositionStats, bool) {
+	m.gets++
+	st, ok := m.stats[key]
+	return st, ok
+}
+
+func (m *mapStore) Put(key TranspositionKey, stats TranspositionStats) {
+	m.stats[key] = stats
+}
+
+func TestTranspositionStore(t *testing.T) {
+	ev := &symmetricEval{tttEval: newTTTEval(3, 1)}
+	store := &mapStore{stats: make(map[TranspositionKey]TranspositionStats)}
+	first := New(ev, ev)
+	first.SetTranspositionStore(store)
+	first.SearchWithBudget(newBoard(3, 3), 1, SearchBudget{MaxIters: 300})
+	if len(store.stats) == 0 {
+		t.Fatalf("expected the search to fill the store")
+	}
+	ch := first.root.children[0]
+	key := first.transpositionKey(ch)
+	if st := store.stats[key]; st.Visits != ch.visits || st.WinScore != ch.winScore {
+		t.Fatalf("expected the stored statistics of %+v, got %+v", ch.visits, st)
+	}
+	before := store.stats[key].Visits
+
+	second := New(ev, ev)
+	second.SetTranspositionStore(store)
+	second.SearchWithBudget(newBoard(3, 3), 1, SearchBudget{MaxIters: 300})
+	if store.gets == 0 {
+		t.Fatalf("expected the store to be consulted")
+	}
+	if after := store.stats[key].Visits; after <= before {
+		t.Fatalf("expected the stored visits to accumulate over %v, got %v", before, after)
+	}
+	if second.root.visits <= first.root.visits {
+		t.Fatalf("expected the root to count the loaded visits over %v, got %v", first.root.visits, second.root.visits)
+	}
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		var sum int64
+		for _, ch := range n.children {
+			sum += ch.visits
+			walk(ch)
+		}
+		// the stored statistics of a loaded node need not add up with those of its children
+		if !n.loaded && sum > n.visits {
+			t.Fatalf("expected the children visits %v not to exceed the %v visits of their parent", sum, n.visits)
+		}
+	}
+	walk(second.root)
+}
+
+func TestTranspositionStoreParallel(t *testing.T) {
+	ev := &symmetricEval{tttEval: newTTTEval(3, 1)}
+	store := &mapStore{stats: make(map[TranspositionKey]TranspositionStats)}
+	s := New(ev, ev)
+	s.SetTranspositionStore(store)
+	s.SearchParallel(newBoard(3, 3), 1, 4, SearchBudget{MaxIters: 100})
+	key := TranspositionKey{Board: ev.CanonicalKey(newBoard(3, 3)), Side: 2}
+	first := store.stats[key].Visits
+	if first < 400 {
+		t.Fatalf("expected the root statistics of every worker, got %v visits", first)
+	}
+	s.SearchParallel(newBoard(3, 3), 1, 4, SearchBudget{MaxIters: 100})
+	if second := store.stats[key].Visits; second < first+400 {
+		t.Fatalf("expected the stored visits to accumulate over %v, got %v", first, second)
+	}
+}
+
+func TestTranspositionStoreRequiresCanonicalizer(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetTranspositionStore(&mapStore{stats: make(map[TranspositionKey]TranspositionStats)})
+	if s.store != nil {
+		t.Fatalf("expected the store to be ignored without a Canonicalizer")
+	}
+}
+
+func TestTranspositionStoreNoCompounding(t *testing.T) {
+	ev := &symmetricEval{tttEval: newTTTEval(3, 1)}
+	store := &mapStore{stats: make(map[TranspositionKey]TranspositionStats)}
+	// stored returns the total stored visits of the root children after a search
+	stored := func() int64 {
+		s := New(ev, ev)
+		s.SetTranspositionStore(store)
+		s.SearchWithBudget(newBoard(3, 3), 1, SearchBudget{MaxIters: 300})
+		var res int64
+		for _, ch := range s.root.children {
+			res += store.stats[s.transpositionKey(ch)].Visits
+		}
+		return res
+	}
+	first := stored()
+	prev := first
+	for i := 0; i < 4; i++ {
+		cur := stored()
+		if cur-prev > 2*first {
+			t.Fatalf("expected every search to add about %v visits, got %v in search %v", first, cur-prev, i+2)
+		}
+		prev = cur
+	}
+}
+
+func TestTranspositionStoreResolvedDisagreement(t *testing.T) {
+	ev := &symmetricEval{tttEval: newTTTEval(3, 1)}
+	for iters := 20; iters < 200; iters++ {
+		store := &mapStore{stats: make(map[TranspositionKey]TranspositionStats)}
+		s := New(ev, ev)
+		s.SetTranspositionStore(store)
+		s.SetResolveDisagreement(1000)
+		s.SearchIterations(newBoard(3, 3), 1, iters)
+		if s.rollouts <= int64(iters) {
+			continue
+		}
+		// the extra iterations of the resolution are stored
+		best := make(map[TranspositionKey]int64)
+		for _, ch := range s.root.children {
+			if key := s.transpositionKey(ch); ch.visits > best[key] {
+				best[key] = ch.visits
+			}
+		}
+		for key, visits := range best {
+			if st := store.stats[key]; st.Visits != visits {
+				t.Fatalf("expected the stored visits %v after the resolution, got %v", visits, st.Visits)
+			}
+		}
+		return
+	}
+	t.Fatal("expected a disagreement for a small budget")
+}
@@ -0,0 +1,196 @@
+package mcts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// treeBinaryMagic starts the binary form of a tree.
+const treeBinaryMagic = "MCTB\x01"
+
+// BinaryNode is a tree node decoded by UnmarshalTreeBinary. Move is the encoded Move, which is
+// nil for the root, and Value is the value of the node from the perspective of Side, rounded to
+// float32 precision.
+type BinaryNode struct {
+	Move     []byte
+	Side     int
+	Visits   int64
+	Value    float64
+	Children []*BinaryNode
+}
+
+// MarshalTreeBinary writes the retained tree down to maxDepth to w in a compact binary form,
+// which is much smaller than MarshalTreeJSON for large trees. Moves are written once to a move
+// table, encoded with MarshalBinary if they implement encoding.BinaryMarshaler, as their String
+// if they implement fmt.Stringer and with fmt's %+v verb otherwise. Each node is then written in
+// depth first order as the index of its Move in the table, its side and visits as varints, its
+// value as a float32 and the number of its children. A maxDepth less than or equal to 0 writes
+// the whole tree. It writes an empty tree if no search has been run yet.
+func (s *MCTS) MarshalTreeBinary(w io.Writer, maxDepth int) error {
+	var moves [][]byte
+	index := make(map[string]uint64)
+	var nodes []byte
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(x uint64) {
+		nodes = append(nodes, buf[:binary.PutUvarint(buf[:], x)]...)
+	}
+	var walk func(n *treeNode) error
+	walk = func(n *treeNode) error {
+		var mi uint64
+		if n.move != nil {
+			enc, err := binaryMove(n.move)
+			if err != nil {
+				return err
+			}
+			i, ok := index[string(enc)]
+			if !ok {
+				moves = append(moves, enc)
+				i = uint64(len(moves))
+				index[string(enc)] = i
+			}
+			mi = i
+		}
+		putUvarint(mi)
+		nodes = append(nodes, buf[:binary.PutVarint(buf[:], int64(n.side))]...)
+		putUvarint(uint64(n.visits))
+		binary.LittleEndian.PutUint32(buf[:4], math.Float32bits(float32(n.value())))
+		nodes = append(nodes, buf[:4]...)
+		if maxDepth > 0 && n.depth >= maxDepth {
+			putUvarint(0)
+			return nil
+		}
+		putUvarint(uint64(len(n.children)))
+		for _, ch := range n.children {
+			if err := walk(ch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if s.root != nil {
+		if err := walk(s.root); err != nil {
+			return err
+		}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(treeBinaryMagic)
+	write := func(x uint64) {
+		bw.Write(buf[:binary.PutUvarint(buf[:], x)])
+	}
+	write(uint64(len(moves)))
+	for _, m := range moves {
+		write(uint64(len(m)))
+		bw.Write(m)
+	}
+	if s.root == nil {
+		bw.WriteByte(0)
+	} else {
+		bw.WriteByte(1)
+		bw.Write(nodes)
+	}
+	return bw.Flush()
+}
+
+// binaryMove returns the encoding of m in the move table of a binary tree.
+func binaryMove(m Move) ([]byte, error) {
+	if bm, ok := m.(encoding.BinaryMarshaler); ok {
+		return bm.MarshalBinary()
+	}
+	if st, ok := m.(fmt.Stringer); ok {
+		return []byte(st.String()), nil
+	}
+	return []byte(fmt.Sprintf("%+v", m)), nil
+}
+
+// UnmarshalTreeBinary reads a tree written by MarshalTreeBinary from r. It returns a nil root
+// for an empty tree.
+func UnmarshalTreeBinary(r io.Reader) (*BinaryNode, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(treeBinaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != treeBinaryMagic {
+		return nil, ErrInvalidTreeData
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, invalidTreeData(err)
+	}
+	var moves [][]byte
+	for i := uint64(0); i < count; i++ {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, invalidTreeData(err)
+		}
+		// the move is copied as it is read so that a corrupt size cannot allocate more than the input
+		var m bytes.Buffer
+		if size > math.MaxInt64 {
+			return nil, invalidTreeData(fmt.Errorf("move size %v out of range", size))
+		}
+		if _, err := io.CopyN(&m, br, int64(size)); err != nil {
+			return nil, invalidTreeData(err)
+		}
+		moves = append(moves, m.Bytes())
+	}
+	hasTree, err := br.ReadByte()
+	if err != nil {
+		return nil, invalidTreeData(err)
+	}
+	if hasTree == 0 {
+		return nil, nil
+	}
+	var read func() (*BinaryNode, error)
+	read = func() (*BinaryNode, error) {
+		mi, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if mi > uint64(len(moves)) {
+			return nil, fmt.Errorf("move index %v out of a table of %v moves", mi, len(moves))
+		}
+		n := &BinaryNode{}
+		if mi > 0 {
+			n.Move = moves[mi-1]
+		}
+		side, err := binary.ReadVarint(br)
+		if err != nil {
+			return nil, err
+		}
+		n.Side = int(side)
+		visits, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		n.Visits = int64(visits)
+		var value [4]byte
+		if _, err := io.ReadFull(br, value[:]); err != nil {
+			return nil, err
+		}
+		n.Value = float64(math.Float32frombits(binary.LittleEndian.Uint32(value[:])))
+		children, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < children; i++ {
+			ch, err := read()
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, ch)
+		}
+		return n, nil
+	}
+	root, err := read()
+	if err != nil {
+		return nil, invalidTreeData(err)
+	}
+	return root, nil
+}
+
+// invalidTreeData wraps err, which was returned while reading a binary tree, in ErrInvalidTreeData.
+func invalidTreeData(err error) error {
+	return fmt.Errorf("%w: %v", ErrInvalidTreeData, err)
+}
@@ -0,0 +1,85 @@
+package mcts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMarshalTreeBinary(t *testing.T) {
+	ev := &labeledEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SearchIterations(newBoard(3, 3), 1, 500)
+	var buf bytes.Buffer
+	if err := s.MarshalTreeBinary(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	root, err := UnmarshalTreeBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compare func(n *treeNode, b *BinaryNode)
+	compare = func(n *treeNode, b *BinaryNode) {
+		if n.move == nil && b.Move != nil || n.move != nil && string(b.Move) != n.move.(*labeledMove).String() {
+			t.Fatalf("expected the move %v, got %q", n.move, b.Move)
+		}
+		if b.Side != n.side || b.Visits != n.visits || len(b.Children) != len(n.children) {
+			t.Fatalf("expected side %v, %v visits and %v children, got %+v", n.side, n.visits, len(n.children), b)
+		}
+		if math.Abs(b.Value-n.value()) > 1e-6*math.Max(1, math.Abs(n.value())) {
+			t.Fatalf("expected the value %v within float32 precision, got %v", n.value(), b.Value)
+		}
+		for i, ch := range n.children {
+			compare(ch, b.Children[i])
+		}
+	}
+	compare(s.root, root)
+
+	json, err := s.MarshalTreeJSON(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	s.MarshalTreeBinary(&buf, 0)
+	if buf.Len() >= len(json) {
+		t.Fatalf("expected the binary tree to be smaller than the %v bytes of JSON, got %v", len(json), buf.Len())
+	}
+}
+
+func TestMarshalTreeBinaryDepth(t *testing.T) {
+	ev := &labeledEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	var buf bytes.Buffer
+	s.MarshalTreeBinary(&buf, 1)
+	if root, err := UnmarshalTreeBinary(&buf); root != nil || err != nil {
+		t.Fatalf("expected an empty tree before searching, got %v, %v", root, err)
+	}
+	s.SearchIterations(newBoard(3, 3), 1, 200)
+	buf.Reset()
+	s.MarshalTreeBinary(&buf, 1)
+	root, err := UnmarshalTreeBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != len(s.root.children) {
+		t.Fatalf("expected %v root children, got %v", len(s.root.children), len(root.Children))
+	}
+	for _, ch := range root.Children {
+		if len(ch.Children) != 0 {
+			t.Fatal("expected no nodes below the depth cap")
+		}
+	}
+	data := buf.Bytes()
+	if _, err := UnmarshalTreeBinary(bytes.NewReader(data[:len(data)/2])); !errors.Is(err, ErrInvalidTreeData) {
+		t.Fatalf("expected truncated data to be invalid, got %v", err)
+	}
+
+	// a move size far beyond the input must not be allocated
+	size := make([]byte, binary.MaxVarintLen64)
+	corrupt := append([]byte(treeBinaryMagic+"\x01"), size[:binary.PutUvarint(size, 1<<40)]...)
+	if _, err := UnmarshalTreeBinary(bytes.NewReader(corrupt)); !errors.Is(err, ErrInvalidTreeData) {
+		t.Fatalf("expected a corrupt move size to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package mcts
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonNode is the JSON form of a tree node.
+type jsonNode struct {
+	Move     interface{} `json:"move"`
+	Side     int         `json:"side"`
+	Visits   int64       `json:"visits"`
+	Value    float64     `json:"value"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// MarshalTreeJSON returns the retained tree down to maxDepth as JSON, for example for an
+// analysis frontend. Every node holds its move, the side that played it, its visits, its value
+// from the perspective of that side and its children, where the root has a null move. A Move
+// that implements json.Marshaler is encoded with it, a Move that implements fmt.Stringer is
+// encoded as its String and any other Move is encoded by encoding/json as is.
+// A maxDepth less than or equal to 0 marshals the whole tree. It returns null if no search has
+// been run yet.
+func (s *MCTS) MarshalTreeJSON(maxDepth int) ([]byte, error) {
+	if s.root == nil {
+		return json.Marshal(nil)
+	}
+	return json.Marshal(toJSONNode(s.root, maxDepth))
+}
+
+func toJSONNode(n *treeNode, maxDepth int) *jsonNode {
+	res := &jsonNode{
+		Move:   jsonMove(n.move),
+		Side:   n.side,
+		Visits: n.visits,
+		Value:  n.value(),
+	}
+	if maxDepth > 0 && n.depth >= maxDepth {
+		return res
+	}
+	for _, ch := range n.children {
+		res.Children = append(res.Children, toJSONNode(ch, maxDepth))
+	}
+	return res
+}
+
+// jsonMove returns the value that m is encoded as.
+func jsonMove(m Move) interface{} {
+	if _, ok := m.(json.Marshaler); ok {
+		return m
+	}
+	if st, ok := m.(fmt.Stringer); ok {
+		return st.String()
+	}
+	return m
+}
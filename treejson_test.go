@@ -0,0 +1,79 @@
+package mcts
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// labeledMove is a tictactoe move that describes itself.
+type labeledMove struct {
+	tttMove
+}
+
+func (m *labeledMove) String() string {
+	return fmt.Sprintf("%v@%v,%v", m.side, m.i, m.j)
+}
+
+// labeledEval is a tictactoe evaluator with labeled moves.
+type labeledEval struct {
+	*tttEval
+}
+
+func (e *labeledEval) Expand(board [][]int, side int) []Move {
+	var res []Move
+	for _, m := range e.tttEval.Expand(board, side) {
+		res = append(res, &labeledMove{tttMove: *m.(*tttMove)})
+	}
+	return res
+}
+
+func (e *labeledEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	if lm, ok := m.(*labeledMove); ok {
+		m = &lm.tttMove
+	}
+	return e.tttEval.ApplyMove(board, currentPlayerSide, m)
+}
+
+func TestMarshalTreeJSON(t *testing.T) {
+	ev := &labeledEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SearchIterations(newBoard(3, 3), 1, 500)
+	data, err := s.MarshalTreeJSON(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type node struct {
+		Move     *string `json:"move"`
+		Visits   int64   `json:"visits"`
+		Children []node  `json:"children"`
+	}
+	var root node
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Move != nil || root.Visits != s.root.visits {
+		t.Fatal("expected the root without a move")
+	}
+	var count func(n node) int
+	count = func(n node) int {
+		res := 1
+		for _, ch := range n.Children {
+			if ch.Move == nil || *ch.Move == "" {
+				t.Fatal("expected a labeled move")
+			}
+			res += count(ch)
+		}
+		return res
+	}
+	want := 1
+	for _, ch := range s.root.children {
+		want += 1 + len(ch.children)
+	}
+	if got := count(root); got != want {
+		t.Fatalf("expected %v nodes down to depth 2, got %v", want, got)
+	}
+	if root.Children[0].Move == nil || *root.Children[0].Move != "1@0,0" {
+		t.Fatalf("expected the move label, got %v", *root.Children[0].Move)
+	}
+}
@@ -0,0 +1,65 @@
+package mcts
+
+import "container/heap"
+
+// TrimToNodes reduces the retained tree to at most maxNodes nodes, for example before reusing
+// it for the next move. The principal variation is always kept, as far as maxNodes allows,
+// followed by the most visited other nodes, and the least visited subtrees are dropped. The
+// Moves of dropped children stay pending and are added to the tree again when their parent is
+// selected by a later search.
+func (s *MCTS) TrimToNodes(maxNodes int) {
+	if s.root == nil || maxNodes < 1 {
+		return
+	}
+	kept := map[*treeNode]bool{s.root: true}
+	pv := []*treeNode{s.root}
+	for n := s.root; len(n.children) > 0 && len(kept) < maxNodes; {
+		n = s.bestChild(n)
+		kept[n] = true
+		pv = append(pv, n)
+	}
+	q := &nodeQueue{}
+	for _, n := range pv {
+		for _, ch := range n.children {
+			if !kept[ch] {
+				heap.Push(q, ch)
+			}
+		}
+	}
+	for len(kept) < maxNodes && q.Len() > 0 {
+		n := heap.Pop(q).(*treeNode)
+		kept[n] = true
+		for _, ch := range n.children {
+			heap.Push(q, ch)
+		}
+	}
+	var trim func(n *treeNode)
+	trim = func(n *treeNode) {
+		children := make([]*treeNode, 0, len(n.children))
+		for _, ch := range n.children {
+			if kept[ch] {
+				children = append(children, ch)
+				trim(ch)
+			} else {
+				n.pending = append(n.pending, ch.move)
+			}
+		}
+		n.children = children
+	}
+	trim(s.root)
+	s.memUsed = treeFootprint(s.root)
+}
+
+// nodeQueue is a max heap of nodes by visits.
+type nodeQueue []*treeNode
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].visits > q[j].visits }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(*treeNode)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,37 @@
+package mcts
+
+import "testing"
+
+func TestTrimToNodes(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	board := newBoard(4, 4)
+	s.Search(copyBoard(board), 1, 0, 0, 2000)
+	if countNodes(s.root) <= 50 {
+		t.Fatal("expected a large tree")
+	}
+	var pv []*treeNode
+	for n := s.root; len(n.children) > 0 && len(pv) < 3; {
+		n = bestChild(n)
+		pv = append(pv, n)
+	}
+	s.TrimToNodes(50)
+	if c := countNodes(s.root); c > 50 {
+		t.Fatalf("expected at most 50 nodes, got %v", c)
+	}
+	for n, i := s.root, 0; i < len(pv); i++ {
+		if bestChild(n) != pv[i] {
+			t.Fatalf("expected the principal variation to survive at depth %v", i+1)
+		}
+		n = pv[i]
+	}
+	if len(s.root.children)+len(s.root.pending) != 16 {
+		t.Fatal("expected dropped root moves to be pending")
+	}
+
+	s.Search(copyBoard(board), 1, 0, 0, 200)
+	if len(s.root.children) != 16 || len(s.root.pending) != 0 {
+		t.Fatalf("expected dropped root moves to be added again, got %v children", len(s.root.children))
+	}
+}
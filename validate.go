@@ -0,0 +1,57 @@
+package mcts
+
+import "fmt"
+
+// SetDebugChecks sets whether rollouts check that every Move chosen by RandomMove or the playout
+// policy is one of the Moves returned by Expand, or the pass Move. An illegal Move panics with an
+// ErrEvaluator whose Phase is "rollout" and whose Err wraps ErrIllegalMove, before ApplyMove
+// fails in a less obvious way or silently accepts it. The checks expand every rollout position,
+// so they are disabled by default.
+func (s *MCTS) SetDebugChecks(debug bool) {
+	s.debugChecks = debug
+}
+
+// checkRolloutMove panics if m is not a legal Move of side on board in a rollout at depth.
+func (s *MCTS) checkRolloutMove(board [][]int, side, depth int, m Move) {
+	if s.isPass(m, side) {
+		return
+	}
+	moves := s.expandAt(board, side, depth)
+	for _, em := range moves {
+		if s.moveEqual(m, em) {
+			return
+		}
+	}
+	err := fmt.Errorf("%w: rollout move %+v of side %v at depth %v is not among the %v moves returned by Expand",
+		ErrIllegalMove, m, side, depth, len(moves))
+	panic(&ErrEvaluator{Phase: "rollout", Err: err})
+}
+
+// Validate runs cheap consistency checks of the Evaluator and the Expander on board for side.
+// It checks that PrevPlayer is the inverse of NextPlayer, that every Move returned by Expand
+// can be applied and that RandomMove returns one of the Moves returned by Expand.
+// It is meant as a diagnostic before searching and returns the first problem found.
+func (s *MCTS) Validate(board [][]int, side int) error {
+	if next := s.ev.NextPlayer(side); s.ev.PrevPlayer(next) != side {
+		return fmt.Errorf("%w: PrevPlayer(NextPlayer(%v)) is %v", ErrInvalidSide, side, s.ev.PrevPlayer(next))
+	}
+	moves := s.ex.Expand(cloneBoard(s.ev, board), side)
+	for i, m := range moves {
+		if _, _, err := s.ev.ApplyMove(cloneBoard(s.ev, board), side, m); err != nil {
+			return &ErrEvaluator{Phase: "validate", Err: fmt.Errorf("expanded move %v can not be applied: %w", i, err)}
+		}
+	}
+	m := s.ev.RandomMove(cloneBoard(s.ev, board), side)
+	if m == nil {
+		if len(moves) > 0 {
+			return fmt.Errorf("mcts: RandomMove returned no move but Expand returned %v moves", len(moves))
+		}
+		return nil
+	}
+	for _, em := range moves {
+		if s.moveEqual(m, em) {
+			return nil
+		}
+	}
+	return fmt.Errorf("mcts: RandomMove returned a move that is not returned by Expand")
+}
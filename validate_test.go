@@ -0,0 +1,98 @@
+package mcts
+
+import (
+	"errors"
+	"testing"
+)
+
+// brokenPlayers has NextPlayer and PrevPlayer that are not inverses.
+type brokenPlayers struct {
+	*tttEval
+}
+
+func (e *brokenPlayers) PrevPlayer(currentPlayerSide int) int {
+	return currentPlayerSide
+}
+
+// rejectingEval rejects every move.
+type rejectingEval struct {
+	*tttEval
+}
+
+func (e *rejectingEval) ApplyMove(board [][]int, currentPlayerSide int, m Move) (bool, int, error) {
+	return false, 0, errors.New("illegal move")
+}
+
+// offBoardEval returns random moves that Expand never returns.
+type offBoardEval struct {
+	*tttEval
+}
+
+func (e *offBoardEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return &tttMove{i: -1, j: -1, side: currentPlayerSide}
+}
+
+// noRandomEval never returns a random move.
+type noRandomEval struct {
+	*tttEval
+}
+
+func (e *noRandomEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	board := newBoard(3, 3)
+	ev := newTTTEval(3, 1)
+	if err := New(ev, ev).Validate(board, 1); err != nil {
+		t.Fatalf("expected a consistent evaluator, got %v", err)
+	}
+	for name, e := range map[string]Evaluator{
+		"players":   &brokenPlayers{ev},
+		"apply":     &rejectingEval{ev},
+		"random":    &offBoardEval{ev},
+		"no random": &noRandomEval{ev},
+	} {
+		if err := New(e, ev).Validate(board, 1); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+	for _, row := range board {
+		for _, v := range row {
+			if v != 0 {
+				t.Fatal("expected Validate not to modify the board")
+			}
+		}
+	}
+}
+
+// cornerEval always plays the top left cell in rollouts, even once it is taken.
+type cornerEval struct {
+	*tttEval
+}
+
+func (e *cornerEval) RandomMove(board [][]int, currentPlayerSide int) Move {
+	return &tttMove{i: 0, j: 0, side: currentPlayerSide}
+}
+
+func TestDebugChecks(t *testing.T) {
+	ev := &cornerEval{newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetMaxRolloutMoves(20)
+	s.SetWarnf(func(format string, args ...interface{}) {})
+	if _, _, err := s.SearchE(newBoard(3, 3), 1, SearchBudget{MaxIters: 10}); err != nil {
+		t.Fatalf("expected the illegal rollout moves to go unnoticed without checks, got %v", err)
+	}
+	s.SetDebugChecks(true)
+	_, _, err := s.SearchE(newBoard(3, 3), 1, SearchBudget{MaxIters: 10})
+	var evErr *ErrEvaluator
+	if !errors.As(err, &evErr) || evErr.Phase != "rollout" || !errors.Is(err, ErrIllegalMove) {
+		t.Fatalf("expected an illegal rollout move error, got %v", err)
+	}
+	ok := newTTTEval(3, 1)
+	s = New(ok, ok)
+	s.SetDebugChecks(true)
+	if _, _, err := s.SearchE(newBoard(3, 3), 1, SearchBudget{MaxIters: 200}); err != nil {
+		t.Fatalf("expected legal rollouts to pass the checks, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package mcts
+
+// NodeView is a read-only view of a node of the search tree, which allows walking the tree
+// retained from the last search. The view reflects the tree as it is, so it changes if the
+// tree is searched again. The zero NodeView is an empty view without children.
+type NodeView struct {
+	n *treeNode
+}
+
+// RootNode returns a view of the root of the last search, or the zero NodeView if no search
+// has been run yet.
+func (s *MCTS) RootNode() NodeView {
+	return NodeView{n: s.root}
+}
+
+// Move returns the Move that leads to the node, which is nil for the root.
+func (v NodeView) Move() Move {
+	if v.n == nil {
+		return nil
+	}
+	return v.n.move
+}
+
+// Visits returns the number of visits of the node.
+func (v NodeView) Visits() int64 {
+	if v.n == nil {
+		return 0
+	}
+	return v.n.visits
+}
+
+// WinScore returns the total evaluation of the node from the perspective of Side.
+func (v NodeView) WinScore() float64 {
+	if v.n == nil {
+		return 0
+	}
+	return v.n.winScore
+}
+
+// Side returns the side that played Move, from whose perspective the statistics of the node are.
+// For the root it is the side before the searched side, which is PrevPlayer of it for a new tree.
+func (v NodeView) Side() int {
+	if v.n == nil {
+		return 0
+	}
+	return v.n.side
+}
+
+// GameOver reports whether the game is over at the node.
+func (v NodeView) GameOver() bool {
+	return v.n != nil && v.n.gameOver
+}
+
+// GameOverReason returns why the game is over at the node, which is ReasonUnknown if it is not
+// over or the Evaluator does not implement GameOverReasoner.
+func (v NodeView) GameOverReason() GameOverReason {
+	if v.n == nil {
+		return ReasonUnknown
+	}
+	return v.n.reason
+}
+
+// Children returns views of the children of the node.
+func (v NodeView) Children() []NodeView {
+	if v.n == nil {
+		return nil
+	}
+	res := make([]NodeView, len(v.n.children))
+	for i, ch := range v.n.children {
+		res[i] = NodeView{n: ch}
+	}
+	return res
+}
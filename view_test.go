@@ -0,0 +1,105 @@
+package mcts
+
+import "testing"
+
+func TestRootNode(t *testing.T) {
+	ev := newTTTEval(3, 1)
+	s := New(ev, ev)
+	if v := s.RootNode(); v.Visits() != 0 || v.Children() != nil {
+		t.Fatal("expected an empty view before searching")
+	}
+	s.SearchIterations(newBoard(3, 3), 1, 500)
+	nodes := 0
+	var walk func(v NodeView, n *treeNode)
+	walk = func(v NodeView, n *treeNode) {
+		nodes++
+		if v.Visits() != n.visits || v.WinScore() != n.winScore || v.Side() != n.side || v.GameOver() != n.gameOver {
+			t.Fatal("expected the view to match the node")
+		}
+		children := v.Children()
+		if len(children) != len(n.children) {
+			t.Fatalf("expected %v children, got %v", len(n.children), len(children))
+		}
+		for i, ch := range children {
+			if ch.Move() != n.children[i].move {
+				t.Fatal("expected the child moves to match")
+			}
+			walk(ch, n.children[i])
+		}
+	}
+	walk(s.RootNode(), s.root)
+	if nodes != countNodes(s.root) {
+		t.Fatalf("expected to walk %v nodes, walked %v", countNodes(s.root), nodes)
+	}
+	var sum int64
+	for _, ch := range s.RootNode().Children() {
+		sum += ch.Visits()
+	}
+	if sum == 0 || sum > s.RootNode().Visits() {
+		t.Fatalf("expected the child visits to add up to at most the root visits, got %v of %v", sum, s.RootNode().Visits())
+	}
+}
+
+// reasonEval is a tictactoe evaluator that reports why its games are over.
+type reasonEval struct {
+	*tttEval
+}
+
+func (e *reasonEval) GameOverReason(board [][]int, winner int) GameOverReason {
+	if winner != 0 {
+		return ReasonWin
+	}
+	return ReasonNoMoves
+}
+
+func TestGameOverReason(t *testing.T) {
+	ev := &reasonEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetKeepSearchingAfterProof(true)
+	s.SearchIterations([][]int{{1, 2, 1}, {1, 2, 2}, {2, 0, 0}}, 1, 50)
+	var wins, full int
+	var walk func(v NodeView)
+	walk = func(v NodeView) {
+		switch {
+		case !v.GameOver():
+			if v.GameOverReason() != ReasonUnknown {
+				t.Fatalf("expected no reason for a running game, got %v", v.GameOverReason())
+			}
+		case v.n.winner != 0:
+			wins++
+			if v.GameOverReason() != ReasonWin {
+				t.Fatalf("expected a won game, got %v", v.GameOverReason())
+			}
+		default:
+			full++
+			if v.GameOverReason() != ReasonNoMoves {
+				t.Fatalf("expected a full board, got %v", v.GameOverReason())
+			}
+		}
+		for _, ch := range v.Children() {
+			walk(ch)
+		}
+	}
+	walk(s.RootNode())
+	if wins == 0 || full == 0 {
+		t.Fatalf("expected won and drawn terminal nodes, got %v and %v", wins, full)
+	}
+
+	// side 1 wins by completing the first row
+	res := s.SearchWithStats([][]int{{1, 1, 0}, {2, 2, 0}, {0, 0, 0}}, 1, 0, 0, 50)
+	if res.Stats[0].Reason != ReasonWin {
+		t.Fatalf("expected the winning move to report its reason, got %v", res.Stats[0].Reason)
+	}
+	if res.Stats[1].Reason != ReasonUnknown {
+		t.Fatalf("expected no reason for a move that does not end the game, got %v", res.Stats[1].Reason)
+	}
+}
+
+func TestGameOverReasonDoublePass(t *testing.T) {
+	g := &passGame{}
+	s := New(g, g)
+	s.Search([][]int{{0}}, 1, 0, 0, 10)
+	if r := s.RootNode().Children()[0].Children()[0].GameOverReason(); r != ReasonDoublePass {
+		t.Fatalf("expected the second pass to end the game by double pass, got %v", r)
+	}
+}
@@ -0,0 +1,145 @@
+package mcts
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// SetProgressiveWidening enables progressive widening, which limits the number of children of
+// a node to ceil(c * visits^alpha), but at least 1. The Moves returned by Expand are ranked by
+// their Eval in descending order and added to the tree in that order as the node is visited more.
+// A c less than or equal to 0, which is the default, disables progressive widening.
+func (s *MCTS) SetProgressiveWidening(c, alpha float64) {
+	s.widenC = c
+	s.widenAlpha = alpha
+}
+
+// SetProgressiveUnpruning limits the number of children of a node to initial, plus one for
+// every threshold of thresholds that its visits have reached. The Moves returned by Expand are
+// ranked like with progressive widening, so the best ranked Moves are added first and the
+// withheld ones are reconsidered as the node gets more visits. It replaces progressive
+// widening while it is set. An initial less than or equal to 0, which is the default,
+// disables progressive unpruning.
+func (s *MCTS) SetProgressiveUnpruning(initial int, thresholds []int64) {
+	s.unpruneInitial = initial
+	s.unpruneThresholds = append([]int64(nil), thresholds...)
+}
+
+// SetExpandBudget sets the time that creating the children of a node may take in one
+// iteration, which applies every Move to a copy of the board. Once it is exceeded, the
+// remaining Moves stay pending and are added when the node is selected again, so that an
+// expensive expansion is spread over several iterations instead of stalling the search.
+// At least one child is added each time. A budget less than or equal to 0, which is the
+// default, creates all children at once.
+func (s *MCTS) SetExpandBudget(d time.Duration) {
+	s.expandBudget = d
+}
+
+// RootMoves returns the Moves of the root children of the last search in expansion order.
+func (s *MCTS) RootMoves() []Move {
+	if s.root == nil {
+		return nil
+	}
+	res := make([]Move, len(s.root.children))
+	for i, ch := range s.root.children {
+		res[i] = ch.move
+	}
+	return res
+}
+
+func (s *MCTS) widening() bool {
+	return s.widenC > 0 || s.unpruneInitial > 0
+}
+
+// allowedChildren returns the number of children that progressive widening or unpruning allows
+// for n. Without either all pending Moves are allowed.
+func (s *MCTS) allowedChildren(n *treeNode) int {
+	if !s.widening() {
+		return len(n.children) + len(n.pending)
+	}
+	if s.unpruneInitial > 0 {
+		k := s.unpruneInitial
+		for _, t := range s.unpruneThresholds {
+			if n.visits >= t {
+				k++
+			}
+		}
+		return k
+	}
+	k := int(math.Ceil(s.widenC * math.Pow(float64(n.visits), s.widenAlpha)))
+	if k < 1 {
+		k = 1
+	}
+	return k
+}
+
+// widen adds pending Moves of n to the tree until the number of children is allowed
+// by progressive widening. Pending Moves can also be left by trimming the tree.
+func (s *MCTS) widen(n *treeNode) {
+	if !n.hasPending() || len(n.children) >= s.allowedChildren(n) {
+		return
+	}
+	parentBoard := s.expansionBoard(n)
+	var t0 time.Time
+	if s.expandBudget > 0 {
+		t0 = s.clock.Now()
+	}
+	for added := 0; len(n.children) < s.allowedChildren(n); added++ {
+		if s.memBudget > 0 && s.memUsed >= s.memBudget {
+			return
+		}
+		if added > 0 && s.expandBudget > 0 && s.clock.Now().Sub(t0) >= s.expandBudget {
+			return
+		}
+		m, ok := s.nextPending(n)
+		if !ok {
+			return
+		}
+		s.addChild(n, &treeNode{}, m, parentBoard)
+	}
+}
+
+// nextPending removes and returns the next pending Move of n, which is pulled from its lazy
+// iterator once the ranked pending Moves are used up.
+func (s *MCTS) nextPending(n *treeNode) (Move, bool) {
+	if len(n.pending) > 0 {
+		m := n.pending[0]
+		n.pending = n.pending[1:]
+		return m, true
+	}
+	for n.lazy != nil {
+		m, ok := n.lazy.Next()
+		if !ok {
+			n.lazy = nil
+			break
+		}
+		if s.moveFilter != nil && !s.moveFilter(m, n.depth+1) {
+			n.filtered = true
+			continue
+		}
+		if s.isChild(n, m) {
+			continue
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// isChild reports whether m is the Move of a child of n.
+func (s *MCTS) isChild(n *treeNode, m Move) bool {
+	for _, ch := range n.children {
+		if s.moveEqual(ch.move, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// rankMoves sorts moves by Eval in descending order, keeping the order of equal Moves.
+func rankMoves(moves []Move) []Move {
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Eval() > moves[j].Eval()
+	})
+	return moves
+}
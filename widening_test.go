@@ -0,0 +1,175 @@
+package mcts
+
+import (
+	"testing"
+	"time"
+)
+
+// rankedEval is a tictactoe evaluator whose moves are evaluated higher towards the
+// end of the board.
+type rankedEval struct {
+	*tttEval
+}
+
+func (e *rankedEval) Expand(board [][]int, side int) []Move {
+	moves := e.tttEval.Expand(board, side)
+	for _, m := range moves {
+		mov := m.(*tttMove)
+		mov.eval = float64(mov.i*len(board)+mov.j) / 100
+	}
+	return moves
+}
+
+func TestRootMoves(t *testing.T) {
+	ev := &rankedEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	if s.RootMoves() != nil {
+		t.Fatal("expected no root moves before a search")
+	}
+	board := newBoard(3, 3)
+	s.Search(copyBoard(board), 1, 0, 0, 20)
+	want := ev.Expand(board, 1)
+	got := s.RootMoves()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v root moves, got %v", len(want), len(got))
+	}
+	for i := range want {
+		if !s.moveEqual(want[i], got[i]) {
+			t.Fatalf("expected root moves in expansion order, got %v at %v", got[i], i)
+		}
+	}
+
+	s = New(ev, ev)
+	s.SetProgressiveWidening(1, 0.5)
+	s.Search(copyBoard(board), 1, 0, 0, 5)
+	got = s.RootMoves()
+	if len(got) == 0 || len(got) >= len(want) {
+		t.Fatalf("expected a subset of the root moves with widening, got %v", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Eval() > got[i-1].Eval() {
+			t.Fatal("expected root moves ranked by evaluation")
+		}
+	}
+	if got[0].Eval() != want[len(want)-1].Eval() {
+		t.Fatal("expected the highest evaluated move first")
+	}
+	if len(got)+len(s.root.pending) != len(want) {
+		t.Fatal("expected the remaining moves to be pending")
+	}
+}
+
+// slowCloneEval is a tictactoe evaluator whose board copies advance a clock by a millisecond.
+type slowCloneEval struct {
+	*tttEval
+	clock *fakeClock
+}
+
+func (e *slowCloneEval) CloneState(board [][]int) [][]int {
+	e.clock.now = e.clock.now.Add(time.Millisecond)
+	return copyBoard(board)
+}
+
+func TestExpandBudget(t *testing.T) {
+	run := func(budget time.Duration) *MCTS {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ev := &slowCloneEval{tttEval: newTTTEval(5, 1), clock: clock}
+		s := New(ev, ev)
+		s.SetClock(clock)
+		s.SetExpandBudget(budget)
+		s.Search(newBoard(8, 8), 1, 60*time.Millisecond, 0, 0)
+		return s
+	}
+	if s := run(0); s.rollouts != 1 {
+		t.Fatalf("expected the full expansion to take the whole budget, got %v iterations", s.rollouts)
+	}
+	s := run(5 * time.Millisecond)
+	if s.rollouts < 5 {
+		t.Fatalf("expected the search to progress during the expansion, got %v iterations", s.rollouts)
+	}
+	if n := len(s.root.children); n == 0 || n+len(s.root.pending) != 64 {
+		t.Fatalf("expected the root moves to be added over several iterations, got %v children", n)
+	}
+}
+
+func TestProgressiveUnpruning(t *testing.T) {
+	ev := &rankedEval{tttEval: newTTTEval(3, 1)}
+	s := New(ev, ev)
+	s.SetTreeReuse(true)
+	s.SetProgressiveUnpruning(2, []int64{50, 200})
+	board := newBoard(3, 3)
+	s.SearchIterations(copyBoard(board), 1, 5)
+	if n := len(s.root.children); n != 2 || s.root.visits >= 50 {
+		t.Fatalf("expected 2 children below the first threshold, got %v", n)
+	}
+	s.SearchIterations(copyBoard(board), 1, 20)
+	if n := len(s.root.children); n != 3 || s.root.visits >= 200 {
+		t.Fatalf("expected a withheld move to be added after the first threshold, got %v children", n)
+	}
+	if m := s.root.children[2].move.(*tttMove); m.i != 2 || m.j != 0 {
+		t.Fatalf("expected the best withheld move to be added, got %v", m)
+	}
+	s.SearchIterations(copyBoard(board), 1, 100)
+	if n := len(s.root.children); n != 4 {
+		t.Fatalf("expected every threshold to add a move, got %v children", n)
+	}
+}
+
+// lazyEval is a tictactoe evaluator whose Moves are pulled lazily in board order.
+type lazyEval struct {
+	*tttEval
+	pulled int
+}
+
+// sliceIterator yields the Moves of a slice and counts them.
+type sliceIterator struct {
+	moves []Move
+	e     *lazyEval
+}
+
+func (it *sliceIterator) Next() (Move, bool) {
+	if len(it.moves) == 0 {
+		return nil, false
+	}
+	m := it.moves[0]
+	it.moves = it.moves[1:]
+	it.e.pulled++
+	return m, true
+}
+
+func (e *lazyEval) ExpandLazy(board [][]int, side int) MoveIterator {
+	return &sliceIterator{moves: e.tttEval.Expand(board, side), e: e}
+}
+
+func TestLazyExpander(t *testing.T) {
+	ev := &lazyEval{tttEval: newTTTEval(5, 1)}
+	s := New(ev, ev)
+	s.SetProgressiveWidening(1, 0.5)
+	if m, _ := s.Search(newBoard(5, 5), 1, 0, 0, 100); m == nil {
+		t.Fatal("expected a move")
+	}
+	// all counts the moves of the expanded nodes, which have one move per empty cell
+	nodes, all := 0, 0
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if len(n.children) > 0 {
+			all += 25 - n.depth
+		}
+		for _, ch := range n.children {
+			nodes++
+			walk(ch)
+		}
+	}
+	walk(s.root)
+	if ev.pulled != nodes {
+		t.Fatalf("expected one pulled move per child, got %v pulls for %v children", ev.pulled, nodes)
+	}
+	if ev.pulled*4 > all {
+		t.Fatalf("expected far fewer pulled moves than the %v moves of the expanded nodes, got %v", all, ev.pulled)
+	}
+	for i, ch := range s.root.children {
+		if m := ch.move.(*tttMove); m.i != i/5 || m.j != i%5 {
+			t.Fatalf("expected the root moves in iterator order, got %+v at %v", m, i)
+		}
+	}
+}